@@ -0,0 +1,102 @@
+// Package atomicfile replaces files so that readers, and a crash, see either the old or the new contents.
+package atomicfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// DefaultMode is the mode of a new file that has no original to copy attributes from.
+const DefaultMode os.FileMode = 0o644
+
+// Write writes the file at path atomically. write is called with a temporary file in the same folder,
+// which is synced to disk and renamed over path, then the folder is synced so the rename is durable.
+//
+// The mode, modification time and, where the process is allowed, owner and group are copied from the file at like,
+// which is usually path itself. If like is "" or doesn't exist the new file has DefaultMode.
+// The access time is set to the modification time, as it isn't portable to read.
+// As the modification time is kept, media servers such as Komga or Kavita that scan for changed files
+// by modification time won't notice the new contents until they rescan everything.
+func Write(path, like string, write func(w io.Writer) error) error {
+	return WriteVerified(path, like, write, nil)
+}
+
+// WriteVerified writes the file at path atomically like Write, but calls verify with the name of the
+// temporary file once it is written. If verify returns an error, path is left as it was.
+func WriteVerified(path, like string, write func(w io.Writer) error, verify func(name string) error) error {
+	var original os.FileInfo
+	if like != "" {
+		fi, err := os.Stat(like)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stat %s: %w", like, err)
+		}
+		original = fi
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed creating temporary file: %w", err)
+	}
+
+	if err = writeTemp(tmp, original, write); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if verify != nil {
+		if err = verify(tmp.Name()); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed moving file: %w", err)
+	}
+
+	if err = syncDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to sync folder: %w", err)
+	}
+	return nil
+}
+
+// writeTemp writes, sets attributes and syncs the temporary file, then closes it.
+func writeTemp(tmp *os.File, original os.FileInfo, write func(w io.Writer) error) error {
+	err := write(tmp)
+	if err == nil {
+		err = setAttributes(tmp, original)
+	}
+	if err == nil {
+		if err = tmp.Sync(); err != nil {
+			err = fmt.Errorf("failed to sync file: %w", err)
+		}
+	}
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close file: %w", closeErr)
+	}
+	return err
+}
+
+// setAttributes copies the mode, owner and modification time of the original file.
+// It is called before the file is synced, so the attributes are as durable as the contents.
+func setAttributes(f *os.File, original os.FileInfo) error {
+	mode := DefaultMode
+	if original != nil {
+		mode = original.Mode().Perm()
+		if err := chown(f, original); err != nil {
+			return fmt.Errorf("failed to set file owner: %w", err)
+		}
+	}
+	if err := f.Chmod(mode); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+	if original != nil {
+		if err := os.Chtimes(f.Name(), original.ModTime(), original.ModTime()); err != nil {
+			return fmt.Errorf("failed to set file times: %w", err)
+		}
+	}
+	return nil
+}
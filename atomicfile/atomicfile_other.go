@@ -0,0 +1,17 @@
+//go:build !unix
+
+package atomicfile
+
+import (
+	"os"
+)
+
+// chown does nothing, as file ownership is not portable.
+func chown(f *os.File, original os.FileInfo) error {
+	return nil
+}
+
+// syncDir does nothing, as folders can't be synced on all platforms.
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,112 @@
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "comic.cbz")
+	if err := os.WriteFile(path, []byte("old"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Write(path, path, func(w io.Writer) error {
+		_, err := w.Write([]byte("new"))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bs, _ := os.ReadFile(path); string(bs) != "new" {
+		t.Errorf("contents = %q, want new", bs)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %v, want %v", fi.Mode().Perm(), os.FileMode(0o640))
+	}
+	if !fi.ModTime().Equal(modified) {
+		t.Errorf("modification time = %v, want %v", fi.ModTime(), modified)
+	}
+}
+
+func TestWrite_new(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comic.cbz")
+	if err := Write(path, "", func(w io.Writer) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != DefaultMode {
+		t.Errorf("mode = %v, want %v", fi.Mode().Perm(), DefaultMode)
+	}
+}
+
+func TestWrite_failure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "comic.cbz")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("failure")
+	err := Write(path, path, func(w io.Writer) error {
+		w.Write([]byte("partial"))
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Write() error = %v, want %v", err, failure)
+	}
+	if bs, _ := os.ReadFile(path); string(bs) != "old" {
+		t.Errorf("contents = %q, want old", bs)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
+
+func TestWriteVerified(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "comic.cbz")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("failure")
+	err := WriteVerified(path, path, func(w io.Writer) error {
+		_, err := w.Write([]byte("new"))
+		return err
+	}, func(name string) error {
+		if bs, _ := os.ReadFile(name); string(bs) != "new" {
+			t.Errorf("verified contents = %q, want new", bs)
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("WriteVerified() error = %v, want %v", err, failure)
+	}
+	if bs, _ := os.ReadFile(path); string(bs) != "old" {
+		t.Errorf("contents = %q, want old", bs)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary file left behind: %v", entries)
+	}
+}
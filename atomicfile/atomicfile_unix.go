@@ -0,0 +1,40 @@
+//go:build unix
+
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// chown gives the file the owner and group of the original file.
+// It isn't an error if the process isn't allowed to, as only privileged processes can give away files.
+func chown(f *os.File, original os.FileInfo) error {
+	st, ok := original.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if int(st.Uid) == os.Geteuid() && int(st.Gid) == os.Getegid() {
+		return nil
+	}
+	err := f.Chown(int(st.Uid), int(st.Gid))
+	if errors.Is(err, os.ErrPermission) {
+		// Still try to keep the group, which the owner may change to any group they are a member of
+		err = f.Chown(-1, int(st.Gid))
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return nil
+	}
+	return err
+}
+
+// syncDir syncs a folder, so a file renamed into it is durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,307 @@
+// Package cbi reads and writes ComicBookInfo metadata, which is JSON stored in the comment of a ZIP archive.
+// Format: https://code.google.com/archive/p/comicbookinfo/wikis/Example.wiki
+package cbi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/blissd/cbz/model"
+	"math"
+	"strings"
+	"time"
+)
+
+// Key is the JSON key holding the ComicBookInfo object.
+const Key = "ComicBookInfo/1.0"
+
+// AppID identifies this tool when writing ComicBookInfo.
+const AppID = "cbz"
+
+type Credit struct {
+	Person  string `json:"person"`
+	Role    string `json:"role"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// ComicBookInfo is the metadata object of the ComicBookInfo/1.0 format.
+type ComicBookInfo struct {
+	Series           string   `json:"series,omitempty"`
+	Title            string   `json:"title,omitempty"`
+	Publisher        string   `json:"publisher,omitempty"`
+	PublicationMonth int64    `json:"publicationMonth,omitempty"`
+	PublicationYear  int64    `json:"publicationYear,omitempty"`
+	Issue            Text     `json:"issue,omitempty"`
+	NumberOfIssues   int64    `json:"numberOfIssues,omitempty"`
+	Volume           int64    `json:"volume,omitempty"`
+	NumberOfVolumes  int64    `json:"numberOfVolumes,omitempty"`
+	Rating           float64  `json:"rating,omitempty"`
+	Genre            string   `json:"genre,omitempty"`
+	Language         string   `json:"language,omitempty"`
+	Country          string   `json:"country,omitempty"`
+	Credits          []Credit `json:"credits,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	Comments         string   `json:"comments,omitempty"`
+}
+
+// Text is a string that some writers store as a JSON number, such as the issue number.
+type Text string
+
+func (t *Text) UnmarshalJSON(bs []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(bs, &n); err == nil {
+		*t = Text(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return err
+	}
+	*t = Text(s)
+	return nil
+}
+
+// Document is the JSON document stored in a ZIP comment.
+type Document struct {
+	AppID        string
+	LastModified string
+	Info         ComicBookInfo
+
+	// Extra holds top-level keys other than appID, lastModified and the ComicBookInfo object, such as "x-" extensions.
+	Extra map[string]json.RawMessage
+}
+
+// IsComicBookInfo reports whether a ZIP comment looks like it holds ComicBookInfo metadata.
+func IsComicBookInfo(comment string) bool {
+	return strings.HasPrefix(strings.TrimSpace(comment), "{") && strings.Contains(comment, Key)
+}
+
+// Unmarshal parses ComicBookInfo metadata from a ZIP comment.
+func Unmarshal(comment string) (*Document, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(comment), &keys); err != nil {
+		return nil, fmt.Errorf("failed to JSON unmarshal ComicBookInfo: %w", err)
+	}
+
+	info, ok := keys[Key]
+	if !ok {
+		return nil, fmt.Errorf("no %s object", Key)
+	}
+
+	doc := Document{}
+	if err := json.Unmarshal(info, &doc.Info); err != nil {
+		return nil, fmt.Errorf("failed to JSON unmarshal %s: %w", Key, err)
+	}
+	if v, ok := keys["appID"]; ok {
+		_ = json.Unmarshal(v, &doc.AppID)
+	}
+	if v, ok := keys["lastModified"]; ok {
+		_ = json.Unmarshal(v, &doc.LastModified)
+	}
+
+	delete(keys, Key)
+	delete(keys, "appID")
+	delete(keys, "lastModified")
+	if len(keys) > 0 {
+		doc.Extra = keys
+	}
+
+	return &doc, nil
+}
+
+// Marshal encodes the document for storing in a ZIP comment.
+func (d *Document) Marshal() (string, error) {
+	keys := map[string]any{}
+	for k, v := range d.Extra {
+		keys[k] = v
+	}
+	keys["appID"] = d.AppID
+	keys["lastModified"] = d.LastModified
+	keys[Key] = d.Info
+
+	bs, err := json.Marshal(keys)
+	if err != nil {
+		return "", fmt.Errorf("failed to JSON marshal ComicBookInfo: %w", err)
+	}
+	return string(bs), nil
+}
+
+// Update replaces the metadata with that from a ComicInfo, and records this tool as the last writer.
+func (d *Document) Update(info *model.ComicInfo) {
+	d.Info.Update(info)
+	d.AppID = AppID
+	d.LastModified = time.Now().Format("2006-01-02 15:04:05")
+}
+
+// FromComicInfo creates a new document from a ComicInfo.
+func FromComicInfo(info *model.ComicInfo) *Document {
+	d := Document{}
+	d.Update(info)
+	return &d
+}
+
+// roles maps ComicBookInfo credit roles to ComicInfo fields, in the order credits are written.
+var roles = []struct {
+	role  string
+	field string
+}{
+	{"Writer", "Writer"},
+	{"Penciller", "Penciller"},
+	{"Inker", "Inker"},
+	{"Colorist", "Colorist"},
+	{"Letterer", "Letterer"},
+	{"Cover Artist", "CoverArtist"},
+	{"Editor", "Editor"},
+	{"Translator", "Translator"},
+}
+
+// roleAliases maps other role names found in the wild to the roles above.
+var roleAliases = map[string]string{
+	"artist":      "Penciller",
+	"penciler":    "Penciller",
+	"pencils":     "Penciller",
+	"inks":        "Inker",
+	"colors":      "Colorist",
+	"colourist":   "Colorist",
+	"letters":     "Letterer",
+	"cover":       "Cover Artist",
+	"coverartist": "Cover Artist",
+	"story":       "Writer",
+	"script":      "Writer",
+	"author":      "Writer",
+}
+
+// fieldForRole finds the ComicInfo field for a credit role. Unknown roles return "".
+func fieldForRole(role string) string {
+	role = strings.TrimSpace(role)
+	if alias, ok := roleAliases[strings.ToLower(role)]; ok {
+		role = alias
+	}
+	for _, r := range roles {
+		if strings.EqualFold(r.role, role) {
+			return r.field
+		}
+	}
+	return ""
+}
+
+// ComicInfo maps ComicBookInfo to ComicInfo.
+// NumberOfVolumes, Country, the primary flag of credits, and credits with unknown roles have no ComicInfo equivalent and are dropped.
+// The rating is rounded to the single decimal place ComicInfo allows.
+func (i *ComicBookInfo) ComicInfo() *model.ComicInfo {
+	info := model.ComicInfo{
+		Series:          i.Series,
+		Title:           i.Title,
+		Publisher:       i.Publisher,
+		Month:           i.PublicationMonth,
+		Year:            i.PublicationYear,
+		Number:          string(i.Issue),
+		Count:           i.NumberOfIssues,
+		Volume:          i.Volume,
+		CommunityRating: model.Rating(math.Round(i.Rating*10) / 10),
+		Genre:           model.ParseList(i.Genre).String(),
+		LanguageISO:     languageCode(i.Language),
+		Tags:            model.List(nil).Add(i.Tags...).String(),
+		Summary:         i.Comments,
+	}
+
+	for _, c := range i.Credits {
+		if name := fieldForRole(c.Role); name != "" {
+			l, _ := info.List(name)
+			_ = info.SetList(name, l.Add(c.Person))
+		}
+	}
+
+	info.Version = info.RequiredVersion()
+	return &info
+}
+
+// Update overwrites the fields that have a ComicInfo equivalent, keeping the rest.
+// Credits for a person already credited in the same ComicInfo field are kept as they were, with their role and primary flag.
+func (i *ComicBookInfo) Update(info *model.ComicInfo) {
+	i.Series = info.Series
+	i.Title = info.Title
+	i.Publisher = info.Publisher
+	i.PublicationMonth = info.Month
+	i.PublicationYear = info.Year
+	i.Issue = Text(info.Number)
+	i.NumberOfIssues = info.Count
+	i.Volume = info.Volume
+	i.Rating = float64(info.CommunityRating)
+	i.Genre = model.ParseList(info.Genre).String()
+	i.Language = languageName(info.LanguageISO)
+	i.Tags = model.ParseList(info.Tags)
+	i.Comments = info.Summary
+
+	// Keep credits whose roles ComicInfo can't represent
+	var credits []Credit
+	for _, c := range i.Credits {
+		if fieldForRole(c.Role) == "" {
+			credits = append(credits, c)
+		}
+	}
+	for _, r := range roles {
+		l, _ := info.List(r.field)
+		for _, person := range l {
+			credits = append(credits, i.credit(person, r.field, r.role))
+		}
+	}
+	i.Credits = credits
+}
+
+// credit finds the existing credit of a person for a ComicInfo field, or creates one with the role.
+func (i *ComicBookInfo) credit(person, field, role string) Credit {
+	for _, c := range i.Credits {
+		if strings.EqualFold(strings.TrimSpace(c.Person), person) && fieldForRole(c.Role) == field {
+			return c
+		}
+	}
+	return Credit{Person: person, Role: role}
+}
+
+// languages maps ISO 639-1 codes to the English language names ComicBookInfo uses.
+var languages = map[string]string{
+	"ar": "Arabic",
+	"cs": "Czech",
+	"da": "Danish",
+	"de": "German",
+	"el": "Greek",
+	"en": "English",
+	"es": "Spanish",
+	"fi": "Finnish",
+	"fr": "French",
+	"he": "Hebrew",
+	"hu": "Hungarian",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"no": "Norwegian",
+	"pl": "Polish",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"sv": "Swedish",
+	"th": "Thai",
+	"tr": "Turkish",
+	"uk": "Ukrainian",
+	"vi": "Vietnamese",
+	"zh": "Chinese",
+}
+
+// languageCode converts a language name to an ISO code. Unknown names are returned unchanged.
+func languageCode(name string) string {
+	for code, n := range languages {
+		if strings.EqualFold(n, name) || strings.EqualFold(code, name) {
+			return code
+		}
+	}
+	return name
+}
+
+// languageName converts an ISO code to a language name. Unknown codes are returned unchanged.
+func languageName(code string) string {
+	if name, ok := languages[strings.ToLower(code)]; ok {
+		return name
+	}
+	return code
+}
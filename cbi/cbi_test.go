@@ -0,0 +1,158 @@
+package cbi
+
+import (
+	"github.com/blissd/cbz/model"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const example = `{
+  "appID": "ComicTagger/1.0.0",
+  "lastModified": "2012-10-23 21:37:12",
+  "ComicBookInfo/1.0": {
+    "series": "Watchmen",
+    "title": "At Midnight, All the Agents...",
+    "publisher": "DC Comics",
+    "publicationMonth": 9,
+    "publicationYear": 1986,
+    "issue": 1,
+    "numberOfIssues": 12,
+    "volume": 1,
+    "numberOfVolumes": 1,
+    "rating": 5,
+    "genre": "Superhero",
+    "language": "English",
+    "country": "United States",
+    "credits": [
+      {"person": "Alan Moore", "role": "Writer", "primary": true},
+      {"person": "Dave Gibbons", "role": "Artist"},
+      {"person": "John Higgins", "role": "Colorist"},
+      {"person": "Len Wein", "role": "Consultant"}
+    ],
+    "tags": ["Vigilantes", "Cold War"],
+    "comments": "A masked vigilante is murdered."
+  },
+  "x-custom": {"a": 1}
+}`
+
+func TestUnmarshal(t *testing.T) {
+	if !IsComicBookInfo(example) {
+		t.Fatal("IsComicBookInfo() = false")
+	}
+
+	doc, err := Unmarshal(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := doc.Info.ComicInfo()
+	want := &model.ComicInfo{
+		Series:          "Watchmen",
+		Title:           "At Midnight, All the Agents...",
+		Publisher:       "DC Comics",
+		Month:           9,
+		Year:            1986,
+		Number:          "1",
+		Count:           12,
+		Volume:          1,
+		CommunityRating: 5,
+		Genre:           "Superhero",
+		LanguageISO:     "en",
+		Tags:            "Vigilantes, Cold War",
+		Summary:         "A masked vigilante is murdered.",
+		Writer:          "Alan Moore",
+		Penciller:       "Dave Gibbons",
+		Colorist:        "John Higgins",
+		Version:         model.SchemaVersion21,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ComicInfo() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDocument_Update(t *testing.T) {
+	doc, err := Unmarshal(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := doc.Info.ComicInfo()
+	info.Writer = "Alan Moore, Someone Else"
+	info.Series = "Watchmen (1986)"
+	doc.Update(info)
+
+	comment, err := doc.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(comment, `"x-custom":{"a":1}`) {
+		t.Errorf("Marshal() lost extension keys: %v", comment)
+	}
+
+	doc, err = Unmarshal(comment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.AppID != AppID {
+		t.Errorf("AppID = %v, want %v", doc.AppID, AppID)
+	}
+	if doc.Info.Series != "Watchmen (1986)" || doc.Info.Country != "United States" || doc.Info.NumberOfVolumes != 1 || doc.Info.Language != "English" {
+		t.Errorf("Update() = %+v", doc.Info)
+	}
+
+	want := []Credit{
+		{Person: "Len Wein", Role: "Consultant"},
+		{Person: "Alan Moore", Role: "Writer", Primary: true},
+		{Person: "Someone Else", Role: "Writer"},
+		{Person: "Dave Gibbons", Role: "Artist"},
+		{Person: "John Higgins", Role: "Colorist"},
+	}
+	if !reflect.DeepEqual(doc.Info.Credits, want) {
+		t.Errorf("Update() credits = %+v, want %+v", doc.Info.Credits, want)
+	}
+
+	// Updating with unchanged metadata gives the same credits back
+	before := append([]Credit(nil), doc.Info.Credits...)
+	doc.Update(doc.Info.ComicInfo())
+	if !reflect.DeepEqual(doc.Info.Credits, before) {
+		t.Errorf("Update() round trip credits = %+v, want %+v", doc.Info.Credits, before)
+	}
+}
+
+func TestComicBookInfo_ComicInfo_rating(t *testing.T) {
+	tests := []struct {
+		rating float64
+		want   model.Rating
+	}{
+		{5, 5},
+		{4.25, 4.3},
+		{3.14159, 3.1},
+		{0, 0},
+	}
+	for _, tt := range tests {
+		info := (&ComicBookInfo{Rating: tt.rating}).ComicInfo()
+		if info.CommunityRating != tt.want {
+			t.Errorf("ComicInfo() rating %v = %v, want %v", tt.rating, info.CommunityRating, tt.want)
+		}
+		if err := info.Validate(); err != nil {
+			t.Errorf("ComicInfo() rating %v: %v", tt.rating, err)
+		}
+	}
+}
+
+func TestIsComicBookInfo(t *testing.T) {
+	tests := []struct {
+		comment string
+		want    bool
+	}{
+		{"", false},
+		{"Created by some tool", false},
+		{`{"ComicBookInfo/1.0": {}}`, true},
+	}
+	for _, tt := range tests {
+		if got := IsComicBookInfo(tt.comment); got != tt.want {
+			t.Errorf("IsComicBookInfo(%q) = %v, want %v", tt.comment, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Package comet models the CoMet metadata format, stored in a CoMet.xml file.
+// Schema: https://www.denvog.com/comet/comet-specification/
+package comet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"github.com/blissd/cbz/model"
+	"io"
+	"strings"
+)
+
+const CoMetXmlName = "CoMet.xml"
+
+// Namespace is the XML namespace of CoMet elements.
+const Namespace = "http://www.denvog.com/comet/"
+
+// SchemaLocation is the location of the CoMet schema, declared on the comet element.
+const SchemaLocation = "http://www.denvog.com/comet/comet.xsd"
+
+type CoMet struct {
+	XMLName          xml.Name `xml:"comet"`
+	Title            string   `xml:"title,omitempty"`
+	Description      string   `xml:"description,omitempty"`
+	Series           string   `xml:"series,omitempty"`
+	Issue            string   `xml:"issue,omitempty"`
+	Volume           int64    `xml:"volume,omitempty"`
+	Publisher        string   `xml:"publisher,omitempty"`
+	Date             string   `xml:"date,omitempty"`
+	Genre            []string `xml:"genre,omitempty"`
+	Character        []string `xml:"character,omitempty"`
+	IsVersionOf      string   `xml:"isVersionOf,omitempty"`
+	Price            string   `xml:"price,omitempty"`
+	Format           string   `xml:"format,omitempty"`
+	Language         string   `xml:"language,omitempty"`
+	Rating           string   `xml:"rating,omitempty"`
+	Rights           string   `xml:"rights,omitempty"`
+	Identifier       string   `xml:"identifier,omitempty"`
+	Pages            int64    `xml:"pages,omitempty"`
+	Creator          []string `xml:"creator,omitempty"`
+	Writer           []string `xml:"writer,omitempty"`
+	Penciller        []string `xml:"penciller,omitempty"`
+	Editor           []string `xml:"editor,omitempty"`
+	CoverDesigner    []string `xml:"coverDesigner,omitempty"`
+	Letterer         []string `xml:"letterer,omitempty"`
+	Inker            []string `xml:"inker,omitempty"`
+	Colorist         []string `xml:"colorist,omitempty"`
+	CoverImage       string   `xml:"coverImage,omitempty"`
+	LastMark         int64    `xml:"lastMark,omitempty"`
+	ReadingDirection string   `xml:"readingDirection,omitempty"`
+}
+
+// MarshalXML writes the comet element with the namespace declarations of the CoMet specification.
+func (c *CoMet) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "comet"}
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:comet"}, Value: Namespace},
+		{Name: xml.Name{Local: "xmlns:xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"},
+		{Name: xml.Name{Local: "xsi:schemaLocation"}, Value: SchemaLocation},
+	}
+	// coMet is CoMet without the custom XML marshalling, to avoid infinite recursion.
+	type coMet CoMet
+	return e.EncodeElement((*coMet)(c), start)
+}
+
+// String returns the CoMet.xml document written by model.MarshalDocument, without the final new line.
+func (c *CoMet) String() string {
+	marshal, err := model.MarshalDocument(c)
+	if err != nil {
+		return "<invalid CoMet.xml>"
+	}
+	return strings.TrimSuffix(string(marshal), "\n")
+}
+
+// IsCoMet reports whether a zip entry is a CoMet.xml file.
+func IsCoMet(file *zip.File) bool {
+	return strings.EqualFold(file.Name, CoMetXmlName)
+}
+
+func Unmarshal(file *zip.File) (*CoMet, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip %s for reading: %w", file.Name, err)
+	}
+	defer r.Close()
+
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+	}
+
+	c := CoMet{}
+	if err = xml.Unmarshal(bs, &c); err != nil {
+		return nil, fmt.Errorf("failed to XML unmarshal %s: %w", file.Name, err)
+	}
+	return &c, nil
+}
+
+// credits maps CoMet credit elements to ComicInfo fields.
+// There is no ComicInfo equivalent of the generic "creator" element.
+func (c *CoMet) credits() []struct {
+	names *[]string
+	field string
+} {
+	return []struct {
+		names *[]string
+		field string
+	}{
+		{&c.Writer, "Writer"},
+		{&c.Penciller, "Penciller"},
+		{&c.Inker, "Inker"},
+		{&c.Colorist, "Colorist"},
+		{&c.Letterer, "Letterer"},
+		{&c.CoverDesigner, "CoverArtist"},
+		{&c.Editor, "Editor"},
+	}
+}
+
+// ComicInfo maps CoMet to ComicInfo.
+// Also returns the names of populated CoMet elements that have no ComicInfo equivalent.
+func (c *CoMet) ComicInfo() (*model.ComicInfo, []string) {
+	info := model.ComicInfo{
+		Title:       c.Title,
+		Summary:     c.Description,
+		Series:      c.Series,
+		Number:      strings.TrimSpace(c.Issue),
+		Publisher:   c.Publisher,
+		Genre:       model.List(nil).Add(c.Genre...).String(),
+		Characters:  model.List(nil).Add(c.Character...).String(),
+		Format:      c.Format,
+		LanguageISO: c.Language,
+	}
+
+	var unmapped []string
+	unmap := func(name string, populated bool) {
+		if populated {
+			unmapped = append(unmapped, name)
+		}
+	}
+
+	// Only keep values ComicInfo allows
+	if valid("Volume", c.Volume) {
+		info.Volume = c.Volume
+	} else {
+		unmap("volume", true)
+	}
+	if valid("PageCount", c.Pages) {
+		info.PageCount = c.Pages
+	} else {
+		unmap("pages", true)
+	}
+	if date, err := model.ParseDate(c.Date); err == nil {
+		info.SetDate(date)
+	} else {
+		unmap("date", true)
+	}
+	if valid("AgeRating", c.Rating) {
+		info.AgeRating = model.AgeRating(c.Rating)
+	} else {
+		unmap("rating", true)
+	}
+	if valid("GTIN", c.Identifier) {
+		info.GTIN = c.Identifier
+	} else {
+		unmap("identifier", true)
+	}
+
+	switch strings.ToLower(c.ReadingDirection) {
+	case "":
+	case "rtl":
+		info.Manga = "YesAndRightToLeft"
+	case "ltr":
+	default:
+		unmap("readingDirection", true)
+	}
+
+	for _, credit := range c.credits() {
+		_ = info.SetList(credit.field, model.List(nil).Add(*credit.names...))
+	}
+
+	unmap("creator", len(c.Creator) > 0)
+	unmap("isVersionOf", c.IsVersionOf != "")
+	unmap("price", c.Price != "")
+	unmap("rights", c.Rights != "")
+	unmap("coverImage", c.CoverImage != "")
+	unmap("lastMark", c.LastMark != 0)
+
+	info.Version = info.RequiredVersion()
+	return &info, unmapped
+}
+
+// FromComicInfo maps ComicInfo to CoMet.
+// Also returns the names of populated ComicInfo fields that have no CoMet equivalent.
+func FromComicInfo(info *model.ComicInfo) (*CoMet, []string) {
+	list := func(s string) []string {
+		return model.ParseList(s)
+	}
+
+	c := CoMet{
+		Title:       info.Title,
+		Description: info.Summary,
+		Series:      info.Series,
+		Issue:       info.Number,
+		Volume:      info.Volume,
+		Publisher:   info.Publisher,
+		Genre:       list(info.Genre),
+		Character:   list(info.Characters),
+		Format:      info.Format,
+		Language:    info.LanguageISO,
+		Rating:      string(info.AgeRating),
+		Identifier:  info.GTIN,
+		Pages:       info.PageCount,
+	}
+
+	mapped := map[string]bool{
+		"Title": true, "Summary": true, "Series": true, "Number": true, "Volume": true, "Publisher": true, "Genre": true,
+		"Characters": true, "Format": true, "LanguageISO": true, "AgeRating": true, "GTIN": true, "PageCount": true,
+		"Year": true, "Month": true, "Day": true, "Manga": true,
+	}
+
+	if info.Year > 0 {
+		c.Date = fmt.Sprintf("%04d", info.Year)
+		if info.Month > 0 {
+			c.Date += fmt.Sprintf("-%02d", info.Month)
+			if info.Day > 0 {
+				c.Date += fmt.Sprintf("-%02d", info.Day)
+			}
+		}
+	}
+
+	switch info.Manga {
+	case "YesAndRightToLeft":
+		c.ReadingDirection = "rtl"
+	case "Yes", "No":
+		c.ReadingDirection = "ltr"
+	}
+
+	for _, credit := range c.credits() {
+		l, _ := info.List(credit.field)
+		*credit.names = l
+		mapped[credit.field] = true
+	}
+
+	var unmapped []string
+	for _, f := range model.Fields() {
+		if !mapped[f.Name] && !f.IsZero(info) {
+			unmapped = append(unmapped, f.Name)
+		}
+	}
+	if len(info.Pages) > 0 {
+		unmapped = append(unmapped, "Pages")
+	}
+
+	return &c, unmapped
+}
+
+// valid reports whether a value is allowed in a ComicInfo field.
+func valid(name string, value any) bool {
+	f, err := model.LookupField(name)
+	return err == nil && f.Validate(value) == nil
+}
@@ -0,0 +1,149 @@
+package comet
+
+import (
+	"encoding/xml"
+	"github.com/blissd/cbz/model"
+	"reflect"
+	"testing"
+)
+
+const example = `<?xml version="1.0" encoding="UTF-8"?>
+<comet xmlns:comet="http://www.denvog.com/comet/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.denvog.com/comet/comet.xsd">
+  <title>The Dark Knight Returns</title>
+  <description>Batman returns.</description>
+  <series>The Dark Knight Returns</series>
+  <issue>1</issue>
+  <volume>1</volume>
+  <publisher>DC Comics</publisher>
+  <date>1986-02</date>
+  <genre>Superhero</genre>
+  <genre>Crime</genre>
+  <character>Batman</character>
+  <character>Robin</character>
+  <price>2.95</price>
+  <language>en</language>
+  <rating>Teen</rating>
+  <identifier>urn:isbn:1563893428</identifier>
+  <pages>48</pages>
+  <creator>Frank Miller</creator>
+  <writer>Frank Miller</writer>
+  <penciller>Frank Miller</penciller>
+  <inker>Klaus Janson</inker>
+  <coverDesigner>Frank Miller</coverDesigner>
+  <readingDirection>ltr</readingDirection>
+</comet>`
+
+func TestCoMet_ComicInfo(t *testing.T) {
+	c := CoMet{}
+	if err := xml.Unmarshal([]byte(example), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	got, unmapped := c.ComicInfo()
+	want := &model.ComicInfo{
+		Title:       "The Dark Knight Returns",
+		Summary:     "Batman returns.",
+		Series:      "The Dark Knight Returns",
+		Number:      "1",
+		Volume:      1,
+		Publisher:   "DC Comics",
+		Year:        1986,
+		Month:       2,
+		Genre:       "Superhero, Crime",
+		Characters:  "Batman, Robin",
+		LanguageISO: "en",
+		AgeRating:   "Teen",
+		PageCount:   48,
+		Writer:      "Frank Miller",
+		Penciller:   "Frank Miller",
+		Inker:       "Klaus Janson",
+		CoverArtist: "Frank Miller",
+		Version:     model.SchemaVersion20,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ComicInfo() = %+v, want %+v", got, want)
+	}
+
+	wantUnmapped := []string{"identifier", "creator", "price"}
+	if !reflect.DeepEqual(unmapped, wantUnmapped) {
+		t.Errorf("ComicInfo() unmapped = %v, want %v", unmapped, wantUnmapped)
+	}
+}
+
+func TestFromComicInfo(t *testing.T) {
+	info := &model.ComicInfo{
+		Title:     "Title",
+		Number:    "1AU",
+		Year:      2021,
+		Month:     3,
+		Day:       14,
+		Writer:    "A, B",
+		Manga:     "YesAndRightToLeft",
+		Notes:     "Notes",
+		AgeRating: "M",
+	}
+
+	got, unmapped := FromComicInfo(info)
+	want := &CoMet{
+		Title:            "Title",
+		Issue:            "1AU",
+		Date:             "2021-03-14",
+		Writer:           []string{"A", "B"},
+		Rating:           "M",
+		ReadingDirection: "rtl",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromComicInfo() = %+v, want %+v", got, want)
+	}
+
+	wantUnmapped := []string{"Notes"}
+	if !reflect.DeepEqual(unmapped, wantUnmapped) {
+		t.Errorf("FromComicInfo() unmapped = %v, want %v", unmapped, wantUnmapped)
+	}
+}
+
+func TestCoMet_String(t *testing.T) {
+	c := &CoMet{Title: "Title", Issue: "1", Writer: []string{"A", "B"}}
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<comet xmlns:comet="http://www.denvog.com/comet/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.denvog.com/comet/comet.xsd">
+  <title>Title</title>
+  <issue>1</issue>
+  <writer>A</writer>
+  <writer>B</writer>
+</comet>`
+	if got := c.String(); got != want {
+		t.Fatalf("String() want: %v, got: %v", want, got)
+	}
+
+	read := CoMet{}
+	if err := xml.Unmarshal([]byte(want), &read); err != nil {
+		t.Fatal(err)
+	}
+	read.XMLName = xml.Name{}
+	if !reflect.DeepEqual(&read, c) {
+		t.Errorf("Unmarshal() = %+v, want %+v", read, c)
+	}
+}
+
+func TestCoMet_ComicInfo_unmapped(t *testing.T) {
+	c := &CoMet{
+		Issue:      "1.5",
+		Volume:     -5,
+		Date:       "2021-02-30",
+		Rating:     "PG-13",
+		Identifier: "123",
+	}
+
+	info, unmapped := c.ComicInfo()
+	if err := info.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if info.Number != "1.5" {
+		t.Errorf("ComicInfo() Number = %v, want 1.5", info.Number)
+	}
+	want := []string{"volume", "date", "rating", "identifier"}
+	if !reflect.DeepEqual(unmapped, want) {
+		t.Errorf("ComicInfo() unmapped = %v, want %v", unmapped, want)
+	}
+}
@@ -0,0 +1,109 @@
+package cometimportcmd
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/blissd/cbz/comet"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/model"
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"io"
+	"strings"
+)
+
+type config struct {
+	out io.Writer
+
+	// overwrite replaces an existing ComicInfo.xml file.
+	overwrite bool
+
+	// removeCoMet removes the CoMet.xml file once converted.
+	removeCoMet bool
+}
+
+// New creates a ffcli.Command for converting CoMet.xml metadata into a ComicInfo.xml file.
+// Operates on multiple CBZ files sequentially.
+func New(out io.Writer) *ffcli.Command {
+	cfg := config{
+		out: out,
+	}
+	fs := flag.NewFlagSet("cbz comet", flag.ExitOnError)
+	fs.BoolVar(&cfg.overwrite, "f", false, "overwrite an existing ComicInfo.xml file")
+	fs.BoolVar(&cfg.removeCoMet, "rm", false, "remove the CoMet.xml file after conversion")
+
+	return &ffcli.Command{
+		Name:       "comet",
+		ShortUsage: "cbz comet <comic.cbz>",
+		ShortHelp:  "Converts CoMet.xml metadata into a ComicInfo.xml file",
+		FlagSet:    fs,
+		Exec:       cfg.exec,
+	}
+}
+
+// exec is the callback for ffcli.Command
+func (c *config) exec(_ context.Context, args []string) error {
+	for _, name := range args {
+		if !strings.HasSuffix(name, ".cbz") {
+			continue
+		}
+		err := c.updateZip(name)
+		if err != nil {
+			return fmt.Errorf("failed converting CoMet in comic archive '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// updateZip converts the CoMet.xml file in a single zip file.
+// Source file will be replaced with updated version.
+func (c *config) updateZip(zipFileName string) error {
+	archive, err := comic.Open(zipFileName)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	if err = c.convert(archive); err != nil {
+		return err
+	}
+	return archive.Save()
+}
+
+// convert adds a ComicInfo.xml file converted from the CoMet.xml file of a comic archive.
+func (c *config) convert(archive *comic.Archive) error {
+	var cometFile *zip.File
+	for _, file := range archive.Files() {
+		if comet.IsCoMet(file) {
+			cometFile = file
+		}
+		if model.IsComicInfo(file.Name) && !c.overwrite {
+			return fmt.Errorf("archive already has a ComicInfo.xml file")
+		}
+	}
+	if cometFile == nil {
+		return fmt.Errorf("no CoMet.xml file found")
+	}
+
+	cm, err := comet.Unmarshal(cometFile)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal CoMet.xml: %w", err)
+	}
+
+	info, unmapped := cm.ComicInfo()
+	if len(unmapped) > 0 {
+		fmt.Fprintf(c.out, "%s: CoMet fields not mapped to ComicInfo.xml: %s\n", archive.Path(), strings.Join(unmapped, ", "))
+	}
+
+	err = info.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to produce a valid ComicInfo.xml: %w", err)
+	}
+
+	if c.removeCoMet {
+		archive.RemoveFile(cometFile.Name)
+	}
+
+	return archive.SetComicInfo(info)
+}
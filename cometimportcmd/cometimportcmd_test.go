@@ -0,0 +1,75 @@
+package cometimportcmd
+
+import (
+	"bytes"
+	"errors"
+	"github.com/blissd/cbz/comet"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/internal/ziptest"
+	"github.com/blissd/cbz/model"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_config_updateZip(t *testing.T) {
+	coMet := ziptest.Entry{Name: comet.CoMetXmlName, Content: `<comet xmlns:comet="http://www.denvog.com/comet/">` +
+		`<title>Title</title><series>Series</series><issue>1</issue><writer>A</writer><writer>B</writer></comet>`}
+	comicInfo := ziptest.Entry{Name: model.ComicInfoXmlName, Content: `<ComicInfo><Title>Existing</Title></ComicInfo>`}
+	converted := model.ComicInfo{Title: "Title", Series: "Series", Number: "1", Writer: "A, B"}
+
+	tests := []struct {
+		name        string
+		entries     []ziptest.Entry
+		overwrite   bool
+		removeCoMet bool
+		want        model.ComicInfo
+		wantCoMet   bool
+		wantErr     bool
+	}{
+		{"Convert", []ziptest.Entry{coMet}, false, false, converted, true, false},
+		{"Convert and remove", []ziptest.Entry{coMet}, false, true, converted, false, false},
+		{"ComicInfo.xml exists", []ziptest.Entry{coMet, comicInfo}, false, false, model.ComicInfo{Title: "Existing"}, true, true},
+		{"Overwrite", []ziptest.Entry{coMet, comicInfo}, true, false, converted, true, false},
+		{"No CoMet.xml", []ziptest.Entry{{Name: "01.jpg", Content: "page"}}, false, false, model.ComicInfo{}, false, true},
+		{"CoMet.xml in a folder", []ziptest.Entry{{Name: "Comic/" + comet.CoMetXmlName, Content: coMet.Content}}, false, false, model.ComicInfo{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "comic.cbz")
+			ziptest.Write(t, name, "", tt.entries...)
+
+			var out bytes.Buffer
+			c := &config{out: &out, overwrite: tt.overwrite, removeCoMet: tt.removeCoMet}
+			if err := c.updateZip(name); (err != nil) != tt.wantErr {
+				t.Fatalf("updateZip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			archive, err := comic.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer archive.Close()
+
+			info, err := archive.ComicInfo()
+			if errors.Is(err, model.ErrNotFound) {
+				info, err = &model.ComicInfo{}, nil
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			info.Version = ""
+			if !reflect.DeepEqual(*info, tt.want) {
+				t.Errorf("updateZip() ComicInfo = %+v, want %+v", *info, tt.want)
+			}
+
+			hasCoMet := false
+			for _, f := range archive.Files() {
+				hasCoMet = hasCoMet || comet.IsCoMet(f)
+			}
+			if hasCoMet != tt.wantCoMet {
+				t.Errorf("updateZip() has CoMet.xml = %v, want %v", hasCoMet, tt.wantCoMet)
+			}
+		})
+	}
+}
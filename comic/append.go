@@ -0,0 +1,200 @@
+package comic
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errNotAppendable is returned when the layout of an archive doesn't allow changes to be appended.
+var errNotAppendable = errors.New("archive can't be updated in place")
+
+const (
+	directoryHeaderSignature = 0x02014b50
+	directoryHeaderLen       = 46
+	directoryEndSignature    = 0x06054b50
+	directoryEndLen          = 22
+	directory64LocSignature  = 0x07064b50
+	directory64LocLen        = 20
+)
+
+// SaveInPlace saves the changes by appending the new and replaced entries, and a new central directory, to the archive file.
+// Unchanged entries are not copied, so this is much faster than Save for large archives.
+// Replaced and removed entries still take up space in the file until it is compacted by Save.
+//
+// Falls back to Save if the archive can't be appended to, such as a ZIP64 archive or one with data after the
+// end of the central directory. Like Save, the archive is then reopened.
+func (a *Archive) SaveInPlace() error {
+	if len(a.edits) == 0 && a.comment == a.r.Comment {
+		return nil
+	}
+	err := a.appendEdits()
+	if errors.Is(err, errNotAppendable) {
+		return a.Save()
+	}
+	if err != nil {
+		return fmt.Errorf("failed updating comic archive in place: %w", err)
+	}
+	return a.reopen()
+}
+
+// appendEdits appends the edits to the archive file.
+// The file is truncated to its original length if writing fails, and keeps its modification time.
+func (a *Archive) appendEdits() error {
+	f, err := os.OpenFile(a.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errNotAppendable, err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+
+	old, err := readDirectory(f, size, 0)
+	if err != nil {
+		return err
+	}
+	if old.end+directoryEndLen+int64(len(a.r.Comment)) != size || old.offset+old.size != old.end {
+		return fmt.Errorf("%w: unexpected data in archive", errNotAppendable)
+	}
+	if len(old.records) != len(a.r.File) {
+		return fmt.Errorf("%w: unexpected central directory", errNotAppendable)
+	}
+
+	// The new entries are written as a ZIP file starting at the end of the archive file,
+	// so their central directory records have the right offsets to be copied.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.SetOffset(size)
+	if err = a.writeEdits(zw); err != nil {
+		return err
+	}
+	added, err := readDirectory(bytes.NewReader(buf.Bytes()), int64(buf.Len()), size)
+	if err != nil {
+		return err
+	}
+	entries := buf.Bytes()[:added.offset-size]
+
+	var records [][]byte
+	for i, record := range old.records {
+		if _, ok := a.edits[a.r.File[i].Name]; !ok {
+			records = append(records, record)
+		}
+	}
+	records = append(records, added.records...)
+
+	offset := size + int64(len(entries))
+	directory := bytes.Join(records, nil)
+	if len(records) >= 0xffff || offset+int64(len(directory)) >= 0xffffffff {
+		return fmt.Errorf("%w: archive needs ZIP64", errNotAppendable)
+	}
+
+	end := make([]byte, directoryEndLen, directoryEndLen+len(a.comment))
+	binary.LittleEndian.PutUint32(end[0:], directoryEndSignature)
+	binary.LittleEndian.PutUint16(end[8:], uint16(len(records)))
+	binary.LittleEndian.PutUint16(end[10:], uint16(len(records)))
+	binary.LittleEndian.PutUint32(end[12:], uint32(len(directory)))
+	binary.LittleEndian.PutUint32(end[16:], uint32(offset))
+	binary.LittleEndian.PutUint16(end[20:], uint16(len(a.comment)))
+	end = append(end, a.comment...)
+
+	data := bytes.Join([][]byte{entries, directory, end}, nil)
+	if _, err = f.WriteAt(data, size); err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		_ = f.Truncate(size)
+		return fmt.Errorf("failed appending to %s: %w", a.path, err)
+	}
+
+	if err = os.Chtimes(a.path, fi.ModTime(), fi.ModTime()); err != nil {
+		return fmt.Errorf("failed to set file times: %w", err)
+	}
+	return nil
+}
+
+// directory is the central directory of a ZIP file.
+type directory struct {
+	// records are the raw central directory file header records.
+	records [][]byte
+
+	// offset and size are the position of the central directory.
+	offset, size int64
+
+	// end is the position of the end of central directory record.
+	end int64
+}
+
+// readDirectory reads the central directory of a ZIP file of the given size.
+// The ZIP file starts at offset base of the file it is part of, which is where positions are from.
+// Returns errNotAppendable for ZIP64 and multi-disk archives.
+func readDirectory(r io.ReaderAt, size, base int64) (*directory, error) {
+	// The end record is followed by a comment of up to 64KiB
+	search := int64(directoryEndLen + 0xffff)
+	if search > size {
+		search = size
+	}
+	buf := make([]byte, search)
+	if _, err := r.ReadAt(buf, size-search); err != nil {
+		return nil, fmt.Errorf("failed to read end of central directory: %w", err)
+	}
+
+	i := len(buf) - directoryEndLen
+	for ; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(buf[i:]) == directoryEndSignature &&
+			i+directoryEndLen+int(binary.LittleEndian.Uint16(buf[i+20:])) == len(buf) {
+			break
+		}
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("%w: no end of central directory", errNotAppendable)
+	}
+	if i >= directory64LocLen && binary.LittleEndian.Uint32(buf[i-directory64LocLen:]) == directory64LocSignature {
+		return nil, fmt.Errorf("%w: ZIP64 archive", errNotAppendable)
+	}
+
+	end := buf[i:]
+	disk, directoryDisk := binary.LittleEndian.Uint16(end[4:]), binary.LittleEndian.Uint16(end[6:])
+	diskCount, count := binary.LittleEndian.Uint16(end[8:]), binary.LittleEndian.Uint16(end[10:])
+	d := &directory{
+		size:   int64(binary.LittleEndian.Uint32(end[12:])),
+		offset: int64(binary.LittleEndian.Uint32(end[16:])),
+		end:    base + size - search + int64(i),
+	}
+	if disk != 0 || directoryDisk != 0 || diskCount != count || count == 0xffff || d.offset == 0xffffffff {
+		return nil, fmt.Errorf("%w: ZIP64 or multi-disk archive", errNotAppendable)
+	}
+	if d.offset < base || d.offset+d.size > d.end {
+		return nil, fmt.Errorf("%w: invalid central directory", errNotAppendable)
+	}
+
+	bs := make([]byte, d.size)
+	if _, err := r.ReadAt(bs, d.offset-base); err != nil {
+		return nil, fmt.Errorf("failed to read central directory: %w", err)
+	}
+	for len(bs) > 0 {
+		if len(bs) < directoryHeaderLen || binary.LittleEndian.Uint32(bs) != directoryHeaderSignature {
+			return nil, fmt.Errorf("%w: invalid central directory", errNotAppendable)
+		}
+		n := directoryHeaderLen +
+			int(binary.LittleEndian.Uint16(bs[28:])) +
+			int(binary.LittleEndian.Uint16(bs[30:])) +
+			int(binary.LittleEndian.Uint16(bs[32:]))
+		if n > len(bs) {
+			return nil, fmt.Errorf("%w: invalid central directory", errNotAppendable)
+		}
+		d.records = append(d.records, bs[:n:n])
+		bs = bs[n:]
+	}
+	if len(d.records) != int(count) {
+		return nil, fmt.Errorf("%w: invalid central directory", errNotAppendable)
+	}
+	return d, nil
+}
@@ -0,0 +1,115 @@
+package comic
+
+import (
+	"bytes"
+	"github.com/blissd/cbz/internal/ziptest"
+	"github.com/blissd/cbz/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestArchive_SaveInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comic.cbz")
+	ziptest.Write(t, path, "comment", []ziptest.Entry{
+		{Name: "01.jpg", Content: "page 1"},
+		{Name: "ComicInfo.xml", Content: `<ComicInfo><Title>Title</Title></ComicInfo>`},
+		{Name: "notes.txt", Content: "notes"},
+	}...)
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if err = archive.SetComicInfo(&model.ComicInfo{Title: "New"}); err != nil {
+		t.Fatal(err)
+	}
+	archive.RemoveFile("notes.txt")
+	archive.SetComment("new comment")
+	if err = archive.SaveInPlace(); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(updated, original) {
+		t.Errorf("SaveInPlace() rewrote the archive instead of appending to it")
+	}
+
+	if got, want := names(archive.Files()), []string{"01.jpg", "ComicInfo.xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() after SaveInPlace() = %v, want %v", got, want)
+	}
+	if got := archive.Comment(); got != "new comment" {
+		t.Errorf("Comment() after SaveInPlace() = %v, want new comment", got)
+	}
+	if got, err := archive.File("01.jpg"); err != nil || string(got) != "page 1" {
+		t.Errorf("File() of unchanged entry = %q, error = %v", got, err)
+	}
+	info, err := archive.ComicInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "New" {
+		t.Errorf("ComicInfo().Title after SaveInPlace() = %v, want New", info.Title)
+	}
+
+	// Compacting removes the replaced entries
+	if err = archive.Save(); err != nil {
+		t.Fatal(err)
+	}
+	compacted, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compacted.Size() >= int64(len(updated)) {
+		t.Errorf("Save() size = %d, want less than %d", compacted.Size(), len(updated))
+	}
+}
+
+func TestArchive_SaveInPlace_fallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comic.cbz")
+	ziptest.Write(t, path, "comment", ziptest.Entry{Name: "01.jpg", Content: "page 1"})
+
+	// Data after the end of the central directory can't be appended to
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write([]byte("trailing data")); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	archive.SetFile("02.jpg", []byte("page 2"))
+	if err = archive.SaveInPlace(); err != nil {
+		t.Fatal(err)
+	}
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(bs, []byte("trailing data")) {
+		t.Errorf("SaveInPlace() appended instead of rewriting")
+	}
+	if got, want := names(archive.Files()), []string{"01.jpg", "02.jpg"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() after SaveInPlace() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,284 @@
+// Package comic opens, edits and saves comic book archives (CBZ files).
+//
+// An Archive is opened from a file, edited in memory and written back with Save, which replaces the file atomically.
+// Entries that are not edited are copied as they are, without being decompressed and compressed again.
+package comic
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/blissd/cbz/atomicfile"
+	"github.com/blissd/cbz/model"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Archive is a comic book archive opened for reading and editing.
+type Archive struct {
+	path string
+	r    *zip.ReadCloser
+
+	// edits are new contents of entries, in the order they were set. A nil value removes the entry.
+	edits     map[string][]byte
+	editOrder []string
+
+	comment string
+}
+
+// Open opens a comic book archive.
+func Open(path string) (*Archive, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open comic archive %s: %w", path, err)
+	}
+	return &Archive{
+		path:    path,
+		r:       r,
+		edits:   map[string][]byte{},
+		comment: r.Comment,
+	}, nil
+}
+
+// Close closes the archive, discarding unsaved changes.
+func (a *Archive) Close() error {
+	return a.r.Close()
+}
+
+// Path is the file the archive was opened from.
+func (a *Archive) Path() string {
+	return a.path
+}
+
+// Files returns the entries of the archive as it was opened, in the order they are stored.
+func (a *Archive) Files() []*zip.File {
+	return a.r.File
+}
+
+// Reader returns the archive as it was opened. A *zip.Reader is also an fs.FS.
+func (a *Archive) Reader() *zip.Reader {
+	return &a.r.Reader
+}
+
+// Pages returns the image entries of the archive as it was opened, in reading order.
+// Reading order is the NaturalLess order of entry paths, not the order entries are stored.
+func (a *Archive) Pages() []*zip.File {
+	var pages []*zip.File
+	for _, file := range a.r.File {
+		if IsImage(file.Name) {
+			pages = append(pages, file)
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return NaturalLess(pages[i].Name, pages[j].Name)
+	})
+	return pages
+}
+
+// File returns the contents of an entry, including unsaved changes.
+// Returns an error wrapping fs.ErrNotExist if there is no such entry.
+func (a *Archive) File(name string) ([]byte, error) {
+	if data, ok := a.edits[name]; ok {
+		if data == nil {
+			return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+		}
+		return data, nil
+	}
+
+	r, err := a.r.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// SetFile adds or replaces an entry. New and replaced entries are written after the unchanged entries.
+func (a *Archive) SetFile(name string, data []byte) {
+	if data == nil {
+		data = []byte{}
+	}
+	a.edit(name, data)
+}
+
+// RemoveFile removes an entry.
+func (a *Archive) RemoveFile(name string) {
+	a.edit(name, nil)
+}
+
+func (a *Archive) edit(name string, data []byte) {
+	if _, ok := a.edits[name]; !ok {
+		a.editOrder = append(a.editOrder, name)
+	}
+	a.edits[name] = data
+}
+
+// Comment returns the ZIP file comment, including unsaved changes.
+func (a *Archive) Comment() string {
+	return a.comment
+}
+
+// SetComment replaces the ZIP file comment.
+func (a *Archive) SetComment(comment string) {
+	a.comment = comment
+}
+
+// ComicInfo reads the ComicInfo.xml file of the archive, found with model.Locate.
+// Returns model.ErrNotFound if there is none.
+func (a *Archive) ComicInfo() (*model.ComicInfo, error) {
+	name, err := a.comicInfoName()
+	if err != nil {
+		return nil, err
+	}
+	data, err := a.File(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := model.Read(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return info, nil
+}
+
+// ComicInfoLenient reads the ComicInfo.xml file of the archive like ComicInfo, but with model.DecodeLenient.
+func (a *Archive) ComicInfoLenient() (*model.ComicInfo, []model.Warning, error) {
+	name, err := a.comicInfoName()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := a.File(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, warnings, err := model.DecodeLenient(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return info, warnings, nil
+}
+
+// SetComicInfo replaces the ComicInfo.xml file, keeping the name and folder of an existing file.
+func (a *Archive) SetComicInfo(info *model.ComicInfo) error {
+	bs, err := model.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ComicInfo.xml: %w", err)
+	}
+
+	name, err := a.comicInfoName()
+	if errors.Is(err, model.ErrNotFound) {
+		name = model.ComicInfoXmlName
+	} else if err != nil {
+		return err
+	}
+
+	a.SetFile(name, bs)
+	return nil
+}
+
+// comicInfoName finds the name of the ComicInfo.xml entry, including unsaved changes.
+func (a *Archive) comicInfoName() (string, error) {
+	for _, name := range a.editOrder {
+		if a.edits[name] != nil && model.IsComicInfo(name) {
+			return name, nil
+		}
+	}
+
+	name, err := model.Locate(a.Reader())
+	if err != nil {
+		return "", err
+	}
+	if data, ok := a.edits[name]; ok && data == nil {
+		return "", model.ErrNotFound
+	}
+	return name, nil
+}
+
+// WriteTo writes the archive with its changes as a ZIP file.
+func (a *Archive) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	zw := zip.NewWriter(cw)
+
+	for _, file := range a.r.File {
+		if _, ok := a.edits[file.Name]; ok {
+			continue
+		}
+		// Copies source file as-is. No-decompression/validation/re-compression.
+		if err := zw.Copy(file); err != nil {
+			return cw.n, fmt.Errorf("failed to add %s: %w", file.Name, err)
+		}
+	}
+
+	if err := a.writeEdits(zw); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// writeEdits writes the new and replaced entries, then the comment, and finishes the ZIP file.
+func (a *Archive) writeEdits(zw *zip.Writer) error {
+	for _, name := range a.editOrder {
+		data := a.edits[name]
+		if data == nil {
+			continue
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		if _, err = fw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := zw.SetComment(a.comment); err != nil {
+		return fmt.Errorf("failed to write ZIP comment: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish ZIP file: %w", err)
+	}
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Save writes the archive with its changes to a temporary file, then replaces the archive file with it.
+// The mode, owner and modification time of the archive file are kept, see atomicfile.Write.
+// The archive is then reopened, so it can be read and edited again.
+func (a *Archive) Save() error {
+	err := atomicfile.Write(a.path, a.path, func(w io.Writer) error {
+		_, err := a.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed writing comic archive: %w", err)
+	}
+
+	return a.reopen()
+}
+
+// reopen replaces the reader of a saved archive, and clears the saved changes.
+func (a *Archive) reopen() error {
+	a.r.Close()
+	r, err := zip.OpenReader(a.path)
+	if err != nil {
+		return fmt.Errorf("failed to reopen comic archive %s: %w", a.path, err)
+	}
+	a.r = r
+	a.edits = map[string][]byte{}
+	a.editOrder = nil
+	a.comment = r.Comment
+	return nil
+}
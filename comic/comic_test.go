@@ -0,0 +1,161 @@
+package comic
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"github.com/blissd/cbz/internal/ziptest"
+	"github.com/blissd/cbz/model"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// pngImage encodes a blank PNG image of the given size.
+func pngImage(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func names(files []*zip.File) []string {
+	var ns []string
+	for _, f := range files {
+		ns = append(ns, f.Name)
+	}
+	return ns
+}
+
+func TestArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comic.cbz")
+	page := string(pngImage(t, 10, 20))
+	ziptest.Write(t, path, "comment", []ziptest.Entry{
+		{Name: "Comic/01.png", Content: page},
+		{Name: "Comic/comicinfo.xml", Content: `<ComicInfo><Title>Title</Title></ComicInfo>`},
+		{Name: "Comic/10.png", Content: page},
+		{Name: "Comic/02.PNG", Content: page},
+		{Name: "notes.txt", Content: "notes"},
+	}...)
+
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if got, want := names(archive.Pages()), []string{"Comic/01.png", "Comic/02.PNG", "Comic/10.png"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Pages() = %v, want %v", got, want)
+	}
+
+	info, err := archive.ComicInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "Title" {
+		t.Errorf("ComicInfo().Title = %v, want Title", info.Title)
+	}
+
+	info.Series = "Series"
+	if err = archive.SetComicInfo(info); err != nil {
+		t.Fatal(err)
+	}
+	archive.SetFile("extra.txt", []byte("extra"))
+	archive.RemoveFile("notes.txt")
+	archive.SetComment("new comment")
+
+	if err = archive.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Comic/01.png", "Comic/10.png", "Comic/02.PNG", "Comic/comicinfo.xml", "extra.txt"}
+	if got := names(archive.Files()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() after Save() = %v, want %v", got, want)
+	}
+	if got := archive.Comment(); got != "new comment" {
+		t.Errorf("Comment() after Save() = %v, want new comment", got)
+	}
+	if _, err = archive.File("notes.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("File() of removed entry error = %v, want os.ErrNotExist", err)
+	}
+	if got, err := archive.File("Comic/01.png"); err != nil || string(got) != page {
+		t.Errorf("File() of unchanged page changed, error = %v", err)
+	}
+
+	saved, err := archive.ComicInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Title != "Title" || saved.Series != "Series" {
+		t.Errorf("ComicInfo() after Save() = %v", saved)
+	}
+
+	pages, err := archive.PageInfo(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPages := []model.ComicPageInfo{
+		{Image: 0, ImageSize: int64(len(page)), ImageWidth: 10, ImageHeight: 20},
+		{Image: 1, ImageSize: int64(len(page)), ImageWidth: 10, ImageHeight: 20},
+		{Image: 2, ImageSize: int64(len(page)), ImageWidth: 10, ImageHeight: 20},
+	}
+	if !reflect.DeepEqual(pages, wantPages) {
+		t.Errorf("PageInfo() = %v, want %v", pages, wantPages)
+	}
+}
+
+func TestArchive_SetComicInfo_new(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comic.cbz")
+	ziptest.Write(t, path, "comment", ziptest.Entry{Name: "01.jpg", Content: ""})
+
+	archive, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+
+	if _, err = archive.ComicInfo(); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("ComicInfo() error = %v, want model.ErrNotFound", err)
+	}
+	if err = archive.SetComicInfo(&model.ComicInfo{Title: "Title"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := archive.ComicInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "Title" {
+		t.Errorf("ComicInfo() of unsaved change = %v", info)
+	}
+	if err = archive.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(archive.Files()), []string{"01.jpg", model.ComicInfoXmlName}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() after Save() = %v, want %v", got, want)
+	}
+}
+
+func TestIsImage(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"01.jpg", true},
+		{"01.JPEG", true},
+		{"folder/01.png", true},
+		{"01.webp", true},
+		{"ComicInfo.xml", false},
+		{"jpg", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsImage(tt.name); got != tt.want {
+				t.Errorf("IsImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package comic
+
+import (
+	"archive/zip"
+	"fmt"
+	"github.com/blissd/cbz/model"
+	"github.com/chai2010/webp"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// IsImage reports whether an entry name is a page image: a JPEG, PNG or WebP file.
+func IsImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".webp":
+		return true
+	}
+	return false
+}
+
+// decodeConfig reads the dimensions of a page image without decoding the whole image.
+func decodeConfig(file *zip.File) (image.Config, error) {
+	r, err := file.Open()
+	if err != nil {
+		return image.Config{}, fmt.Errorf("failed to open image file '%v': %w", file.Name, err)
+	}
+	defer r.Close()
+	return ImageConfig(file.Name, r)
+}
+
+// ImageConfig reads the dimensions of a page image without decoding the whole image.
+// The image format is chosen by the extension of name.
+func ImageConfig(name string, r io.Reader) (image.Config, error) {
+	var config image.Config
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg":
+		config, err = jpeg.DecodeConfig(r)
+	case ".png":
+		config, err = png.DecodeConfig(r)
+	case ".webp":
+		config, err = webp.DecodeConfig(r)
+	default:
+		err = fmt.Errorf("not an image")
+	}
+	if err != nil {
+		return image.Config{}, fmt.Errorf("failed to decode image '%v': %w", name, err)
+	}
+	return config, nil
+}
+
+// PageInfo computes the Pages of a ComicInfo from the page images, in reading order.
+// Values of existing pages, such as Type, are kept. Image, ImageSize, ImageWidth and ImageHeight are computed.
+func (a *Archive) PageInfo(existing []model.ComicPageInfo) ([]model.ComicPageInfo, error) {
+	files := a.Pages()
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no pages in comic archive")
+	}
+
+	pages := make([]model.ComicPageInfo, len(files))
+	for i, file := range files {
+		if i < len(existing) {
+			pages[i] = existing[i]
+		}
+		config, err := decodeConfig(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed updating page: %w", err)
+		}
+		pages[i].Image = i
+		pages[i].ImageSize = int64(file.UncompressedSize64)
+		pages[i].ImageWidth = config.Width
+		pages[i].ImageHeight = config.Height
+	}
+	return pages, nil
+}
+
+// InferDoubles computes the Pages and PageCount of a ComicInfo, marking pages about twice
+// the median page width as double page spreads.
+func (a *Archive) InferDoubles(info *model.ComicInfo) error {
+	pages, err := a.PageInfo(info.Pages)
+	if err != nil {
+		return err
+	}
+
+	// compute median average page width and a range with tolerance for double page width
+	widths := make([]int, len(pages))
+	for i, p := range pages {
+		widths[i] = p.ImageWidth
+	}
+
+	sort.Ints(widths)
+	middle := len(widths) / 2
+	doublePageWidth := widths[middle] * 2
+
+	loDoublePageWidth, hiDoublePageWidth := int(float64(doublePageWidth)*0.8), int(float64(doublePageWidth)*1.2)
+
+	for i := range pages {
+		if pages[i].ImageWidth >= loDoublePageWidth && pages[i].ImageWidth <= hiDoublePageWidth {
+			pages[i].DoublePage = true
+		}
+	}
+
+	info.PageCount = int64(len(pages))
+	info.Pages = pages
+	return nil
+}
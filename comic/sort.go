@@ -0,0 +1,93 @@
+package comic
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NaturalLess reports whether entry path a is before b in reading order.
+// Paths are compared folder by folder, so pages of a folder stay together.
+// Runs of digits are compared by their numeric value, so "page9" is before "page10",
+// and letters are compared ignoring case.
+// Paths that differ only in case or leading zeros are ordered by their text, so the ordering is total.
+func NaturalLess(a, b string) bool {
+	if c := naturalCompare(a, b); c != 0 {
+		return c < 0
+	}
+	return a < b
+}
+
+// naturalCompare compares two paths folder by folder, returning -1, 0 or +1.
+func naturalCompare(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareSegment(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
+// compareSegment compares a single folder or file name, with runs of digits compared as numbers.
+func compareSegment(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			var na, nb string
+			na, a = digitRun(a)
+			nb, b = digitRun(b)
+			if c := compareNumbers(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		ra, sa := utf8.DecodeRuneInString(a)
+		rb, sb := utf8.DecodeRuneInString(b)
+		la, lb := unicode.ToLower(ra), unicode.ToLower(rb)
+		switch {
+		case la < lb:
+			return -1
+		case la > lb:
+			return 1
+		}
+		a, b = a[sa:], b[sb:]
+	}
+	switch {
+	case a == "" && b != "":
+		return -1
+	case a != "" && b == "":
+		return 1
+	}
+	return 0
+}
+
+// compareNumbers compares two runs of digits by value, ignoring leading zeros.
+func compareNumbers(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func digitRun(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,39 @@
+package comic
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"Numeric runs", "page9.jpg", "page10.jpg", true},
+		{"Numeric runs reversed", "page10.jpg", "page9.jpg", false},
+		{"Leading zeros", "page007.jpg", "page10.jpg", true},
+		{"Case", "Page2.jpg", "page10.jpg", true},
+		{"Folder before later folder", "ch2/10.jpg", "ch10/1.jpg", true},
+		{"Folder order over file order", "b/1.jpg", "a/2.jpg", false},
+		{"Same", "01.jpg", "01.jpg", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NaturalLess(tt.a, tt.b); got != tt.want {
+				t.Errorf("NaturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNaturalLess_sort(t *testing.T) {
+	got := []string{"page10.jpg", "Chapter 2/01.jpg", "page9.jpg", "chapter 10/01.jpg", "page09.jpg", "Chapter 2/10.jpg", "Chapter 2/2.jpg", "cover.jpg"}
+	want := []string{"Chapter 2/01.jpg", "Chapter 2/2.jpg", "Chapter 2/10.jpg", "chapter 10/01.jpg", "cover.jpg", "page09.jpg", "page9.jpg", "page10.jpg"}
+	sort.Slice(got, func(i, j int) bool { return NaturalLess(got[i], got[j]) })
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted = %v, want %v", got, want)
+	}
+}
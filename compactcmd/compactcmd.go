@@ -0,0 +1,72 @@
+package compactcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/blissd/cbz/comic"
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"io"
+	"os"
+	"strings"
+)
+
+type config struct {
+	out io.Writer
+}
+
+// New creates a ffcli.Command for compacting comic archives updated in place,
+// removing the space left by replaced and removed entries.
+// Operates on multiple CBZ files sequentially.
+func New(out io.Writer) *ffcli.Command {
+	cfg := config{
+		out: out,
+	}
+	fs := flag.NewFlagSet("cbz compact", flag.ExitOnError)
+
+	return &ffcli.Command{
+		Name:       "compact",
+		ShortUsage: "cbz compact <comic.cbz>",
+		ShortHelp:  "Rewrites comic archives updated with 'cbz set -inplace', reclaiming unused space",
+		FlagSet:    fs,
+		Exec:       cfg.exec,
+	}
+}
+
+// exec is the callback for ffcli.Command
+func (c *config) exec(_ context.Context, args []string) error {
+	for _, name := range args {
+		if !strings.HasSuffix(name, ".cbz") {
+			continue
+		}
+		if err := c.compact(name); err != nil {
+			return fmt.Errorf("failed compacting comic archive '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// compact rewrites a single comic archive, keeping only the entries in its central directory.
+func (c *config) compact(zipFileName string) error {
+	before, err := os.Stat(zipFileName)
+	if err != nil {
+		return err
+	}
+
+	archive, err := comic.Open(zipFileName)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	if err = archive.Save(); err != nil {
+		return err
+	}
+
+	after, err := os.Stat(zipFileName)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.out, "%s: %d -> %d bytes\n", zipFileName, before.Size(), after.Size())
+	return nil
+}
@@ -0,0 +1,76 @@
+package compactcmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/internal/ziptest"
+	"github.com/blissd/cbz/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_config_compact(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "comic.cbz")
+	ziptest.Write(t, name, "", ziptest.Entry{Name: "01.jpg", Content: "page"})
+
+	archive, err := comic.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"First", "Second"} {
+		if err = archive.SetComicInfo(&model.ComicInfo{Title: title}); err != nil {
+			t.Fatal(err)
+		}
+		if err = archive.SaveInPlace(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := archive.ComicInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	c := &config{out: &out}
+	if err = c.compact(name); err != nil {
+		t.Fatalf("compact() error = %v", err)
+	}
+
+	after, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size() >= before.Size() {
+		t.Errorf("compact() size = %d, want less than %d", after.Size(), before.Size())
+	}
+
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		t.Fatalf("compact() wrote an unreadable archive: %v", err)
+	}
+	_ = r.Close()
+
+	archive, err = comic.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	got, err := archive.ComicInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compact() ComicInfo = %+v, want %+v", got, want)
+	}
+}
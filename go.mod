@@ -6,4 +6,5 @@ require (
 	github.com/chai2010/webp v1.1.1
 	github.com/gen2brain/go-unarr v0.1.6
 	github.com/peterbourgon/ff/v3 v3.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
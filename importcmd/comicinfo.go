@@ -0,0 +1,179 @@
+package importcmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/model"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxImageHeader is how much of a page image is kept to read its dimensions.
+const maxImageHeader = 1 << 20
+
+// fieldValue is a field=value argument, set in the generated ComicInfo.xml file.
+type fieldValue struct {
+	field *model.Field
+	value any
+}
+
+// parseFieldValue parses a field=value argument.
+func parseFieldValue(arg string) (fieldValue, error) {
+	name, value, ok := strings.Cut(arg, "=")
+	if !ok {
+		return fieldValue{}, fmt.Errorf("malformed metadata: '%v'", arg)
+	}
+	field, err := model.LookupField(name)
+	if err != nil {
+		return fieldValue{}, fmt.Errorf("malformed metadata: '%v': %w", arg, err)
+	}
+	typedValue, err := field.Parse(value)
+	if err != nil {
+		return fieldValue{}, fmt.Errorf("field %s has invalid value %s: %w", field.Name, value, err)
+	}
+	return fieldValue{field: field, value: typedValue}, nil
+}
+
+// isFieldValue reports whether an argument is a field=value argument rather than an archive:
+// it isn't an existing file, and the text before the "=" is a ComicInfo field.
+// So a missing archive with a "=" in its name is still reported as missing.
+func isFieldValue(arg string) bool {
+	if _, err := os.Stat(arg); err == nil {
+		return false
+	}
+	name, _, ok := strings.Cut(arg, "=")
+	if !ok {
+		return false
+	}
+	_, err := model.LookupField(name)
+	return err == nil
+}
+
+// buildInfo builds the ComicInfo.xml file of an imported archive, without the pages.
+// It starts from the ComicInfo.xml file of the archive, if there is one, without its invalid values,
+// so metadata the schema doesn't allow never stops an import. Empty fields are then filled
+// from the file name, and the field=value arguments are set.
+func (c *config) buildInfo(inputName string, source []byte) (*model.ComicInfo, error) {
+	info := &model.ComicInfo{}
+	if source != nil {
+		var warnings []model.Warning
+		var err error
+		info, warnings, err = model.DecodeLenient(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ComicInfo.xml: %w", err)
+		}
+		for _, w := range warnings {
+			_, _ = fmt.Fprintf(c.out, "%s: warning: %v\n", inputName, w)
+		}
+		for _, fe := range dropInvalid(info) {
+			_, _ = fmt.Fprintf(c.out, "%s: warning: dropped ComicInfo.xml %v\n", inputName, fe)
+		}
+	}
+
+	if err := info.Merge(model.ParseFileName(inputName), model.MergeFillEmpty); err != nil {
+		return nil, fmt.Errorf("failed to add metadata from file name: %w", err)
+	}
+
+	for _, fv := range c.fields {
+		if err := fv.field.Set(info, fv.value); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", fv.field.Name, err)
+		}
+	}
+	return info, nil
+}
+
+// dropInvalid clears the values of a ComicInfo that the schema doesn't allow, and returns why each was dropped.
+// Page values other than Type are left, as setPages replaces them.
+func dropInvalid(info *model.ComicInfo) []*model.FieldError {
+	var dropped []*model.FieldError
+	for {
+		var verr *model.ValidationError
+		if !errors.As(info.Validate(), &verr) {
+			return dropped
+		}
+		n := len(dropped)
+		for _, fe := range verr.Errors {
+			if dropValue(info, fe.Field) {
+				dropped = append(dropped, fe)
+			}
+		}
+		// Clearing a value, such as Month, can make another invalid, such as Day, so validate again.
+		if len(dropped) == n {
+			return dropped
+		}
+	}
+}
+
+// dropValue clears the value at a FieldError path, and reports whether it was cleared.
+func dropValue(info *model.ComicInfo, path string) bool {
+	var i int
+	if _, err := fmt.Sscanf(path, "Pages[%d].Type", &i); err == nil {
+		if i < 0 || i >= len(info.Pages) || info.Pages[i].Type == "" {
+			return false
+		}
+		info.Pages[i].Type = ""
+		return true
+	}
+
+	f, err := model.LookupField(path)
+	if err != nil || f.IsZero(info) {
+		return false
+	}
+	var zero any
+	switch f.Kind {
+	case model.KindInt:
+		zero = int64(0)
+	case model.KindFloat:
+		zero = 0.0
+	case model.KindDate:
+		zero = model.Date{}
+	default:
+		zero = ""
+	}
+	return f.Set(info, zero) == nil
+}
+
+// setPages sets the Pages and PageCount of a ComicInfo from the copied page images, in reading order.
+// Values of existing pages, such as Type, are kept, as with comic.Archive.PageInfo.
+func setPages(info *model.ComicInfo, entries []entry) {
+	var images []*entry
+	for i := range entries {
+		if e := &entries[i]; e.skipped == "" && comic.IsImage(e.target) {
+			images = append(images, e)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return comic.NaturalLess(images[i].target, images[j].target)
+	})
+
+	pages := make([]model.ComicPageInfo, len(images))
+	for i, e := range images {
+		if i < len(info.Pages) {
+			pages[i] = info.Pages[i]
+		}
+		pages[i].Image = i
+		pages[i].ImageSize = e.size
+		pages[i].ImageWidth = e.width
+		pages[i].ImageHeight = e.height
+	}
+	info.Pages = pages
+	info.PageCount = int64(len(pages))
+}
+
+// headWriter keeps the first bytes written to it, up to max, and discards the rest.
+type headWriter struct {
+	buf []byte
+	max int
+}
+
+func (h *headWriter) Write(p []byte) (int, error) {
+	if n := h.max - len(h.buf); n > 0 {
+		if len(p) < n {
+			n = len(p)
+		}
+		h.buf = append(h.buf, p[:n]...)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,142 @@
+package importcmd
+
+import (
+	"bytes"
+	"github.com/blissd/cbz/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_buildInfo(t *testing.T) {
+	genre, err := parseFieldValue("Genre=Drama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	c := &config{out: &out, fields: []fieldValue{genre}}
+
+	source := []byte(`<ComicInfo><Series>From XML</Series><Writer>W</Writer></ComicInfo>`)
+	got, err := c.buildInfo("Saga 055 (2018).cbr", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &model.ComicInfo{Series: "From XML", Writer: "W", Number: "55", Year: 2018, Genre: "Drama", Version: model.SchemaVersion20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildInfo() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_buildInfo_dropsInvalid(t *testing.T) {
+	var out bytes.Buffer
+	c := &config{out: &out}
+
+	source := []byte(`<ComicInfo><Series>From XML</Series><AgeRating>PG-13</AgeRating><Month>13</Month><Day>5</Day>` +
+		`<Pages><Page Image="0" Type="Poster"/></Pages></ComicInfo>`)
+	got, err := c.buildInfo("Saga 055.cbr", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = got.Validate(); err != nil {
+		t.Errorf("buildInfo() Validate() = %v", err)
+	}
+	want := &model.ComicInfo{Series: "From XML", Number: "55", Pages: []model.ComicPageInfo{{}}, Version: model.SchemaVersion20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildInfo() = %+v, want %+v", got, want)
+	}
+	for _, field := range []string{"AgeRating", "Month", "Day", "Pages[0].Type"} {
+		if !strings.Contains(out.String(), "dropped ComicInfo.xml "+field+":") {
+			t.Errorf("buildInfo() output doesn't warn %s was dropped:\n%s", field, out.String())
+		}
+	}
+}
+
+func Test_parseFieldValue(t *testing.T) {
+	tests := []struct {
+		arg     string
+		wantErr bool
+	}{
+		{"Series=Saga", false},
+		{"Volume=2", false},
+		{"Volume=two", true},
+		{"Seires=Saga", true},
+		{"Series", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			if _, err := parseFieldValue(tt.arg); (err != nil) != tt.wantErr {
+				t.Errorf("parseFieldValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_isFieldValue(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "Title=Saga.rar")
+	if err := os.WriteFile(existing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		arg  string
+		want bool
+	}{
+		{"Field", "Title=Saga", true},
+		{"Alias", "AlternativeSeries=Saga", true},
+		{"Missing archive", filepath.Join(dir, "Saga=1.rar"), false},
+		{"Existing archive", existing, false},
+		{"Archive", filepath.Join(dir, "Saga.rar"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isFieldValue(tt.arg); got != tt.want {
+				t.Errorf("isFieldValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setPages(t *testing.T) {
+	info := &model.ComicInfo{Pages: []model.ComicPageInfo{{Type: "FrontCover"}}}
+	entries := []entry{
+		{name: "10.jpg", target: "10.jpg", size: 10, width: 100, height: 200},
+		{name: "2.jpg", target: "2.jpg", size: 2, width: 100, height: 200},
+		{name: "notes.txt", target: "notes.txt", size: 5},
+		{name: "Thumbs.db", skipped: "junk"},
+	}
+	setPages(info, entries)
+
+	want := model.ArrayOfComicPageInfo{
+		{Image: 0, Type: "FrontCover", ImageSize: 2, ImageWidth: 100, ImageHeight: 200},
+		{Image: 1, ImageSize: 10, ImageWidth: 100, ImageHeight: 200},
+	}
+	if !reflect.DeepEqual(info.Pages, want) {
+		t.Errorf("setPages() Pages = %+v, want %+v", info.Pages, want)
+	}
+	if info.PageCount != 2 {
+		t.Errorf("setPages() PageCount = %v, want 2", info.PageCount)
+	}
+}
+
+func Test_setPages_duplicates(t *testing.T) {
+	entries := []entry{
+		{name: "01.jpg", size: 1},
+		{name: "01.jpg", size: 2},
+		{name: "02.jpg", size: 3},
+	}
+	planEntries(entries, false)
+	info := &model.ComicInfo{}
+	setPages(info, entries)
+
+	want := model.ArrayOfComicPageInfo{
+		{Image: 0, ImageSize: 1},
+		{Image: 1, ImageSize: 2},
+		{Image: 2, ImageSize: 3},
+	}
+	if !reflect.DeepEqual(info.Pages, want) {
+		t.Errorf("setPages() Pages = %+v, want %+v", info.Pages, want)
+	}
+}
@@ -0,0 +1,251 @@
+package importcmd
+
+import (
+	"fmt"
+	"github.com/blissd/cbz/model"
+	"github.com/gen2brain/go-unarr"
+	"io"
+	"path"
+	"strings"
+)
+
+// entry is an entry of an archive being imported.
+type entry struct {
+	// name is the path of the entry in the archive being imported.
+	name string
+
+	// size is the uncompressed size of the entry.
+	size int64
+
+	// crc32 is the checksum of the entry, set when it is copied.
+	crc32 uint32
+
+	// width and height are the dimensions of a page image, set when it is copied.
+	width, height int
+
+	// carryOver is set on the ComicInfo.xml entry whose content is carried over to the generated ComicInfo.xml file.
+	carryOver bool
+
+	// target is the name of the entry in the CBZ file. Blank if the entry is skipped.
+	target string
+
+	// skipped is why the entry is skipped, if it is.
+	skipped string
+}
+
+// junkNames are files added by operating systems and file managers, which are not part of a comic.
+var junkNames = map[string]bool{
+	"thumbs.db":   true,
+	"desktop.ini": true,
+	".ds_store":   true,
+}
+
+// isJunk reports whether an entry is a file added by an operating system or file manager,
+// such as Thumbs.db, .DS_Store, a macOS resource fork or anything in a __MACOSX folder.
+func isJunk(name string) bool {
+	base := path.Base(name)
+	if junkNames[strings.ToLower(base)] || strings.HasPrefix(base, "._") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if dir == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// maxComicInfoSize is the largest ComicInfo.xml file that is carried over to a generated ComicInfo.xml file.
+const maxComicInfoSize = 1 << 20
+
+// listEntries reads the names and sizes of the entries of an archive, without uncompressing them.
+func listEntries(input *unarr.Archive) ([]entry, error) {
+	var entries []entry
+	for {
+		err := input.Entry()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed moving to next archive entry: %w", err)
+		}
+		entries = append(entries, entry{
+			name: strings.TrimPrefix(strings.ReplaceAll(input.Name(), `\`, "/"), "./"),
+			size: int64(input.Size()),
+		})
+	}
+	return entries, nil
+}
+
+// planEntries decides the target name of each entry, or why it is skipped.
+// Folders and junk are skipped. Otherwise, the folder structure is kept unless flatten is set.
+// Entries that would have the same name in the CBZ file are given a unique name, so no page is lost.
+func planEntries(entries []entry, flatten bool) {
+	folders := map[string]bool{}
+	for _, e := range entries {
+		for dir := path.Dir(cleanName(e.name)); dir != "."; dir = path.Dir(dir) {
+			folders[dir] = true
+		}
+	}
+
+	var kept []*entry
+	for i := range entries {
+		e := &entries[i]
+		target := cleanName(e.name)
+		switch {
+		case target == "" || strings.HasSuffix(e.name, "/") || folders[target]:
+			e.skipped = "folder"
+		case isJunk(target):
+			e.skipped = "junk"
+		default:
+			e.target = target
+			kept = append(kept, e)
+		}
+	}
+
+	if flatten {
+		flattenEntries(kept)
+	}
+	uniqueTargets(kept)
+}
+
+// cleanName is the path of an entry inside the CBZ file, so it can't be extracted outside of the folder it is extracted to.
+// Backslashes are folder separators, and a leading "/" and ".." elements are removed. Returns "" for the root folder.
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, `\`, "/")), "/")
+}
+
+// uniqueTargets renames entries with the same target as an earlier entry, such as "01.jpg" and "01.jpg",
+// or "a/b_c.jpg" and "a_b/c.jpg" when flattened, by adding a number to the name: "01_2.jpg".
+// The renamed entry sorts after the original with comic.NaturalLess, so page order is kept.
+func uniqueTargets(kept []*entry) {
+	used := map[string]bool{}
+	for _, e := range kept {
+		used[e.target] = true
+	}
+
+	seen := map[string]bool{}
+	for _, e := range kept {
+		if !seen[e.target] {
+			seen[e.target] = true
+			continue
+		}
+		ext := path.Ext(e.target)
+		stem := strings.TrimSuffix(e.target, ext)
+		for n := 2; ; n++ {
+			if target := fmt.Sprintf("%s_%d%s", stem, n, ext); !used[target] {
+				e.target = target
+				break
+			}
+		}
+		used[e.target], seen[e.target] = true, true
+	}
+}
+
+// flattenEntries sets the target of entries to a name in the root of the CBZ file.
+// Entries keep their base name, unless two entries have the same base name.
+// Then entries in folders are prefixed by their folder path below the folder shared by all entries,
+// such as "ch1_01.jpg" and "ch2_01.jpg", so reading order is kept.
+func flattenEntries(kept []*entry) {
+	bases := map[string]int{}
+	for _, e := range kept {
+		bases[path.Base(e.target)]++
+	}
+	collisions := false
+	for _, n := range bases {
+		if n > 1 {
+			collisions = true
+		}
+	}
+
+	root := commonFolder(kept)
+	for _, e := range kept {
+		dir := strings.TrimPrefix(strings.TrimPrefix(path.Dir(e.target), root), "/")
+		e.target = path.Base(e.target)
+		if collisions && dir != "" && dir != "." {
+			e.target = strings.ReplaceAll(dir, "/", "_") + "_" + e.target
+		}
+	}
+}
+
+// commonFolder is the longest folder path shared by the targets of all entries, or "" if there is none.
+func commonFolder(entries []*entry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	root := path.Dir(entries[0].target)
+	for _, e := range entries[1:] {
+		for root != "." && !strings.HasPrefix(e.target, root+"/") {
+			root = path.Dir(root)
+		}
+	}
+	if root == "." {
+		return ""
+	}
+	return root
+}
+
+// checkSizes returns an error if a copied entry is larger than maxEntrySize,
+// or all copied entries together are larger than maxTotalSize. A maximum of 0 is no limit.
+func checkSizes(entries []entry, maxEntrySize, maxTotalSize int64) error {
+	var total int64
+	for _, e := range entries {
+		if e.skipped != "" {
+			continue
+		}
+		if maxEntrySize > 0 && e.size > maxEntrySize {
+			return fmt.Errorf("entry %s is %d bytes, more than the maximum of %d bytes", e.name, e.size, maxEntrySize)
+		}
+		total += e.size
+		if maxTotalSize > 0 && total > maxTotalSize {
+			return fmt.Errorf("entries are more than the maximum total of %d bytes", maxTotalSize)
+		}
+	}
+	return nil
+}
+
+// entryReader streams the current entry of an unarr.Archive.
+// unarr fails to uncompress more bytes than are left in an entry, so reads are limited to what is left.
+type entryReader struct {
+	input     *unarr.Archive
+	name      string
+	remaining int64
+}
+
+func (r *entryReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.input.Read(p)
+	r.remaining -= int64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to uncompress %s: %w", r.name, err)
+	}
+	return n, nil
+}
+
+// replaceComicInfo skips the ComicInfo.xml entries, as a ComicInfo.xml file is generated instead.
+// The shallowest one that isn't too large is marked to be carried over.
+func replaceComicInfo(entries []entry) {
+	var carried *entry
+	depth := -1
+	for i := range entries {
+		e := &entries[i]
+		if e.skipped != "" || !model.IsComicInfo(e.name) {
+			continue
+		}
+		if d := strings.Count(cleanName(e.name), "/"); e.size <= maxComicInfoSize && (depth < 0 || d < depth) {
+			carried, depth = e, d
+		}
+		e.skipped, e.target = "replaced by generated ComicInfo.xml", ""
+	}
+	if carried != nil {
+		carried.carryOver = true
+	}
+}
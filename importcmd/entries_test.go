@@ -0,0 +1,157 @@
+package importcmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_isJunk(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"01.jpg", false},
+		{"ch1/Thumbs.db", true},
+		{"thumbs.db", true},
+		{".DS_Store", true},
+		{"desktop.ini", true},
+		{"ch1/._01.jpg", true},
+		{"__MACOSX/ch1/01.jpg", true},
+		{"ComicInfo.xml", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isJunk(tt.name); got != tt.want {
+				t.Errorf("isJunk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_planEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		flatten bool
+		want    []entry
+	}{
+		{
+			"Keep folders",
+			[]string{"ch1", "ch1/01.jpg", "ch2/01.jpg", "ch2/Thumbs.db"},
+			false,
+			[]entry{
+				{name: "ch1", skipped: "folder"},
+				{name: "ch1/01.jpg", target: "ch1/01.jpg"},
+				{name: "ch2/01.jpg", target: "ch2/01.jpg"},
+				{name: "ch2/Thumbs.db", skipped: "junk"},
+			},
+		},
+		{
+			"Flatten without collisions",
+			[]string{"Comic/01.jpg", "Comic/02.jpg"},
+			true,
+			[]entry{
+				{name: "Comic/01.jpg", target: "01.jpg"},
+				{name: "Comic/02.jpg", target: "02.jpg"},
+			},
+		},
+		{
+			"Flatten with collisions",
+			[]string{"Comic/ch1/01.jpg", "Comic/ch2/01.jpg", "Comic/cover.jpg"},
+			true,
+			[]entry{
+				{name: "Comic/ch1/01.jpg", target: "ch1_01.jpg"},
+				{name: "Comic/ch2/01.jpg", target: "ch2_01.jpg"},
+				{name: "Comic/cover.jpg", target: "cover.jpg"},
+			},
+		},
+		{
+			"Duplicate",
+			[]string{"01.jpg", "01.jpg", "01_2.jpg"},
+			false,
+			[]entry{
+				{name: "01.jpg", target: "01.jpg"},
+				{name: "01.jpg", target: "01_3.jpg"},
+				{name: "01_2.jpg", target: "01_2.jpg"},
+			},
+		},
+		{
+			"Flatten to the same name",
+			[]string{"a/b_c.jpg", "a_b/c.jpg", "a/c.jpg"},
+			true,
+			[]entry{
+				{name: "a/b_c.jpg", target: "a_b_c.jpg"},
+				{name: "a_b/c.jpg", target: "a_b_c_2.jpg"},
+				{name: "a/c.jpg", target: "a_c.jpg"},
+			},
+		},
+		{
+			"Paths outside the archive",
+			[]string{"../x.jpg", "/etc/y.jpg", "ch1/../../z.jpg", `..\w.jpg`, "./.."},
+			false,
+			[]entry{
+				{name: "../x.jpg", target: "x.jpg"},
+				{name: "/etc/y.jpg", target: "etc/y.jpg"},
+				{name: "ch1/../../z.jpg", target: "z.jpg"},
+				{name: `..\w.jpg`, target: "w.jpg"},
+				{name: "./..", skipped: "folder"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := make([]entry, len(tt.names))
+			for i, name := range tt.names {
+				entries[i].name = name
+			}
+			planEntries(entries, tt.flatten)
+			if !reflect.DeepEqual(entries, tt.want) {
+				t.Errorf("planEntries() = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkSizes(t *testing.T) {
+	entries := []entry{
+		{name: "01.jpg", size: 100, target: "01.jpg"},
+		{name: "02.jpg", size: 200, target: "02.jpg"},
+		{name: "Thumbs.db", size: 1000, skipped: "junk"},
+	}
+	tests := []struct {
+		name         string
+		maxEntrySize int64
+		maxTotalSize int64
+		wantErr      bool
+	}{
+		{"No limits", 0, 0, false},
+		{"Within limits", 200, 300, false},
+		{"Entry too large", 150, 0, true},
+		{"Total too large", 0, 299, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkSizes(entries, tt.maxEntrySize, tt.maxTotalSize); (err != nil) != tt.wantErr {
+				t.Errorf("checkSizes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_replaceComicInfo(t *testing.T) {
+	entries := []entry{
+		{name: "ComicInfo.xml", target: "ComicInfo.xml", size: maxComicInfoSize + 1},
+		{name: "Comic/extra/ComicInfo.xml", target: "Comic/extra/ComicInfo.xml", size: 10},
+		{name: "Comic/comicinfo.xml", target: "Comic/comicinfo.xml", size: 10},
+		{name: "Comic/01.jpg", target: "Comic/01.jpg", size: 10},
+	}
+	replaceComicInfo(entries)
+	for i, e := range entries {
+		if want := i == 2; e.carryOver != want {
+			t.Errorf("replaceComicInfo() carryOver of %s = %v, want %v", e.name, e.carryOver, want)
+		}
+		if want := i < 3; (e.skipped != "" && e.target == "") != want {
+			t.Errorf("replaceComicInfo() skipped %s = %v, want %v", e.name, e.skipped, want)
+		}
+	}
+}
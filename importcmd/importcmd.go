@@ -0,0 +1,407 @@
+package importcmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/blissd/cbz/atomicfile"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/model"
+	"github.com/gen2brain/go-unarr"
+	"github.com/peterbourgon/ff/v3/ffcli"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type config struct {
+	out io.Writer
+
+	// flatten puts all entries in the root of the CBZ file instead of keeping the folder structure.
+	flatten bool
+
+	// maxEntrySize is the largest entry that is imported, in bytes. 0 is no limit.
+	maxEntrySize byteSize
+
+	// maxTotalSize is the largest total size of the imported entries, in bytes. 0 is no limit.
+	maxTotalSize byteSize
+
+	// overwrite replaces an existing CBZ file.
+	overwrite bool
+
+	// removeSource deletes the imported archive once the CBZ file is compared with it.
+	removeSource bool
+
+	// moveSource is a folder to move the imported archive to once the CBZ file is compared with it.
+	moveSource string
+
+	// comicInfo generates a ComicInfo.xml file.
+	comicInfo bool
+
+	// fields are set in the generated ComicInfo.xml file.
+	fields []fieldValue
+}
+
+const (
+	defaultMaxEntrySize = 256 << 20
+	defaultMaxTotalSize = 16 << 30
+)
+
+// format is the archive format of a file to import, detected from its content.
+type format string
+
+const (
+	formatRar   format = "RAR"
+	format7z    format = "7z"
+	formatTar   format = "tar"
+	formatZip   format = "ZIP"
+	formatOther format = ""
+)
+
+// extensions are the file extensions of archives that can be imported.
+// They are removed from the name of the imported file before adding ".cbz".
+var extensions = []string{".cbr", ".cb7", ".cbt", ".cbz", ".rar", ".7z", ".tar", ".zip"}
+
+// New creates a ffcli.Command for converting comic archives in other formats into CBZ files.
+// Operates on multiple files sequentially.
+func New(out io.Writer) *ffcli.Command {
+
+	cfg := config{
+		out:          out,
+		maxEntrySize: defaultMaxEntrySize,
+		maxTotalSize: defaultMaxTotalSize,
+		comicInfo:    true,
+	}
+	fs := flag.NewFlagSet("cbz import", flag.ExitOnError)
+	fs.Var(&cfg.maxEntrySize, "max-entry-size", "largest entry to import, in bytes or with a K, M or G suffix. 0 is no limit.")
+	fs.Var(&cfg.maxTotalSize, "max-total-size", "largest total size of the imported entries, in bytes or with a K, M or G suffix. 0 is no limit.")
+	fs.BoolVar(&cfg.flatten, "flatten", false, "put all pages in the root of the CBZ file, prefixing pages with the same name by their folder")
+	fs.BoolVar(&cfg.overwrite, "f", false, "overwrite an existing CBZ file")
+	fs.BoolVar(&cfg.removeSource, "rm", false, "delete the imported archive once the CBZ file is compared with it")
+	fs.StringVar(&cfg.moveSource, "mv", "", "move the imported archive to a folder once the CBZ file is compared with it")
+	fs.BoolVar(&cfg.comicInfo, "comicinfo", true, "generate a ComicInfo.xml file from the archive's ComicInfo.xml, the file name, the pages and field=value arguments")
+
+	return &ffcli.Command{
+		Name:       "import",
+		ShortUsage: "cbz import [field=value ...] <comic.cbr|comic.cb7|comic.cbt|archive.rar|archive.7z|archive.tar|archive.zip>",
+		ShortHelp:  "Imports RAR, 7z, tar and ZIP archives, such as CBR, CB7 and CBT files, and converts them into CBZ files.",
+		FlagSet:    fs,
+		Exec:       cfg.exec,
+	}
+}
+
+// exec is the callback for ffcli.Command
+func (c *config) exec(_ context.Context, args []string) error {
+	if c.removeSource && c.moveSource != "" {
+		return fmt.Errorf("-rm and -mv can't be used together")
+	}
+	if c.moveSource != "" {
+		if fi, err := os.Stat(c.moveSource); err != nil || !fi.IsDir() {
+			return fmt.Errorf("-mv is not a folder: %v", c.moveSource)
+		}
+	}
+
+	// field=value arguments are set in ComicInfo.xml, other arguments are archives
+	var names []string
+	for _, arg := range args {
+		if isFieldValue(arg) {
+			fv, err := parseFieldValue(arg)
+			if err != nil {
+				return err
+			}
+			c.fields = append(c.fields, fv)
+			continue
+		}
+		names = append(names, arg)
+	}
+	if len(c.fields) > 0 && !c.comicInfo {
+		return fmt.Errorf("field=value arguments need a generated ComicInfo.xml file")
+	}
+
+	for _, name := range names {
+		if err := c.importArchive(name); err != nil {
+			return fmt.Errorf("failed importing '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// importArchive converts a single archive into a CBZ file next to it.
+func (c *config) importArchive(inputName string) error {
+	f, err := detectFileFormat(inputName)
+	if err != nil {
+		return err
+	}
+	if f == formatOther {
+		return fmt.Errorf("not a RAR, 7z, tar or ZIP archive")
+	}
+
+	cbzName := outputName(inputName)
+	if cbzName == inputName {
+		return fmt.Errorf("already a CBZ file")
+	}
+	if _, err = os.Stat(cbzName); err == nil && !c.overwrite {
+		return fmt.Errorf("%s already exists, use -f to overwrite it", cbzName)
+	}
+
+	entries, err := readEntries(inputName)
+	if err != nil {
+		return err
+	}
+	planEntries(entries, c.flatten)
+
+	var build func(source []byte) (*model.ComicInfo, error)
+	if c.comicInfo {
+		replaceComicInfo(entries)
+		build = func(source []byte) (*model.ComicInfo, error) {
+			return c.buildInfo(inputName, source)
+		}
+	}
+
+	if err = checkSizes(entries, int64(c.maxEntrySize), int64(c.maxTotalSize)); err != nil {
+		return err
+	}
+
+	// The archive is only removed or moved once the CBZ file is compared with it
+	compare := c.removeSource || c.moveSource != ""
+	info, err := writeCBZ(inputName, cbzName, entries, build, compare)
+	if err != nil {
+		return err
+	}
+
+	c.report(inputName, entries)
+	if info != nil {
+		_, _ = fmt.Fprintf(c.out, "%s: generated %s with %d pages\n", inputName, model.ComicInfoXmlName, info.PageCount)
+	}
+	_, _ = fmt.Fprintf(c.out, "%s: imported and verified %s archive to %s\n", inputName, f, cbzName)
+	if compare {
+		_, _ = fmt.Fprintf(c.out, "%s: compared %s with the archive\n", inputName, cbzName)
+	}
+
+	switch {
+	case c.removeSource:
+		if err = os.Remove(inputName); err != nil {
+			return fmt.Errorf("failed to remove imported archive: %w", err)
+		}
+		_, _ = fmt.Fprintf(c.out, "%s: removed\n", inputName)
+	case c.moveSource != "":
+		target, err := moveFile(inputName, c.moveSource)
+		if err != nil {
+			return fmt.Errorf("failed to move imported archive: %w", err)
+		}
+		_, _ = fmt.Fprintf(c.out, "%s: moved to %s\n", inputName, target)
+	}
+	return nil
+}
+
+// writeCBZ converts an archive into a CBZ file, then verifies it, and if compare is set compares it with the archive.
+// If build is not nil, the ComicInfo it returns is added as a ComicInfo.xml file, with its pages set, and returned.
+// The CBZ file gets the mode, owner and modification time of the archive.
+// It is verified before it replaces an existing CBZ file.
+func writeCBZ(inputName, cbzName string, entries []entry, build func(source []byte) (*model.ComicInfo, error), compare bool) (*model.ComicInfo, error) {
+	input, err := unarr.NewArchive(inputName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer input.Close()
+
+	var info *model.ComicInfo
+	var comicInfo []byte
+	err = atomicfile.WriteVerified(cbzName, inputName, func(w io.Writer) error {
+		info, comicInfo, err = convert(input, entries, build, w)
+		return err
+	}, func(name string) error {
+		if err := verify(name, entries, comicInfo); err != nil {
+			return err
+		}
+		if compare {
+			return compareSource(inputName, name, entries)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// readEntries lists the entries of an archive, without uncompressing them.
+func readEntries(inputName string) ([]entry, error) {
+	input, err := unarr.NewArchive(inputName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer input.Close()
+	return listEntries(input)
+}
+
+// report prints the entries that were skipped or renamed, and a count of each.
+func (c *config) report(inputName string, entries []entry) {
+	var copied, skipped, renamed int
+	for _, e := range entries {
+		switch {
+		case e.skipped != "":
+			skipped++
+			_, _ = fmt.Fprintf(c.out, "%s: skipped %s (%s)\n", inputName, e.name, e.skipped)
+		case e.target != e.name:
+			renamed++
+			_, _ = fmt.Fprintf(c.out, "%s: renamed %s -> %s\n", inputName, e.name, e.target)
+		}
+		if e.skipped == "" {
+			copied++
+		}
+	}
+	_, _ = fmt.Fprintf(c.out, "%s: %d entries copied, %d skipped, %d renamed\n", inputName, copied, skipped, renamed)
+}
+
+// detectFileFormat detects the archive format of a file.
+func detectFileFormat(name string) (format, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return formatOther, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer f.Close()
+
+	// The tar magic is at offset 257 of the first header
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return formatOther, fmt.Errorf("failed to read input file: %w", err)
+	}
+	return detectFormat(head[:n]), nil
+}
+
+// detectFormat detects an archive format from the first bytes of a file.
+func detectFormat(head []byte) format {
+	switch {
+	case bytes.HasPrefix(head, []byte("Rar!\x1a\x07")):
+		return formatRar
+	case bytes.HasPrefix(head, []byte("7z\xbc\xaf\x27\x1c")):
+		return format7z
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return formatZip
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		return formatTar
+	}
+	return formatOther
+}
+
+// outputName is the name of the CBZ file an archive is imported to.
+// A known archive extension is replaced with ".cbz", and other extensions are kept.
+func outputName(inputName string) string {
+	ext := filepath.Ext(inputName)
+	for _, e := range extensions {
+		if strings.EqualFold(ext, e) {
+			return strings.TrimSuffix(inputName, ext) + ".cbz"
+		}
+	}
+	return inputName + ".cbz"
+}
+
+// convert copies the entries of an archive into a ZIP file, with the names planned by planEntries,
+// uncompressing each entry once. The entries must be listed from the same archive, in the same order.
+// The checksum of each copied entry, and the dimensions of page images, are set.
+//
+// If build is not nil, it is called with the content of the carryOver entry, or nil if there is none.
+// The ComicInfo it returns has its pages set and is written last as a ComicInfo.xml file.
+// The ComicInfo and the ComicInfo.xml file are returned.
+func convert(input *unarr.Archive, entries []entry, build func(source []byte) (*model.ComicInfo, error), w io.Writer) (*model.ComicInfo, []byte, error) {
+	output := zip.NewWriter(w)
+
+	var source []byte
+	for i := range entries {
+		e := &entries[i]
+		if err := input.Entry(); err != nil {
+			return nil, nil, fmt.Errorf("failed moving to next archive entry: %w", err)
+		}
+
+		// Limits are checked with the listed sizes, so an entry must not be larger when read
+		if int64(input.Size()) != e.size {
+			return nil, nil, fmt.Errorf("archive entry %s changed size", e.name)
+		}
+
+		if e.carryOver {
+			var err error
+			if source, err = io.ReadAll(&entryReader{input: input, name: e.name, remaining: e.size}); err != nil {
+				return nil, nil, err
+			}
+		}
+		if e.skipped != "" {
+			continue
+		}
+
+		fw, err := output.Create(e.target)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create ZIP entry: %w", err)
+		}
+		h := crc32.NewIEEE()
+		head := &headWriter{max: maxImageHeader}
+		r := io.TeeReader(&entryReader{input: input, name: e.name, remaining: e.size}, io.MultiWriter(h, head))
+		if _, err = io.Copy(fw, r); err != nil {
+			return nil, nil, fmt.Errorf("failed to write ZIP entry: %w", err)
+		}
+		e.crc32 = h.Sum32()
+
+		// Pages without dimensions are still listed in ComicInfo.xml
+		if config, err := comic.ImageConfig(e.target, bytes.NewReader(head.buf)); err == nil {
+			e.width, e.height = config.Width, config.Height
+		}
+	}
+
+	var info *model.ComicInfo
+	var comicInfo []byte
+	if build != nil {
+		var err error
+		if info, err = build(source); err != nil {
+			return nil, nil, err
+		}
+		setPages(info, entries)
+		if err = info.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("failed to produce a valid ComicInfo.xml: %w", err)
+		}
+		if comicInfo, err = model.Marshal(info); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal ComicInfo.xml: %w", err)
+		}
+		fw, err := output.Create(model.ComicInfoXmlName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create ComicInfo.xml: %w", err)
+		}
+		if _, err = fw.Write(comicInfo); err != nil {
+			return nil, nil, fmt.Errorf("failed to write ComicInfo.xml: %w", err)
+		}
+	}
+
+	if err := output.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to finish ZIP file: %w", err)
+	}
+	return info, comicInfo, nil
+}
+
+// byteSize is a flag.Value for a size in bytes, with an optional K, M or G suffix for KiB, MiB or GiB.
+type byteSize int64
+
+func (b *byteSize) String() string {
+	return strconv.FormatInt(int64(*b), 10)
+}
+
+func (b *byteSize) Set(s string) error {
+	multipliers := map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+	number, multiplier := s, int64(1)
+	if s != "" {
+		if m, ok := multipliers[strings.ToUpper(s[len(s)-1:])]; ok {
+			number, multiplier = s[:len(s)-1], m
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/multiplier {
+		return fmt.Errorf("invalid size: %v", s)
+	}
+	*b = byteSize(n * multiplier)
+	return nil
+}
@@ -0,0 +1,172 @@
+package importcmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/blissd/cbz/internal/ziptest"
+	"github.com/blissd/cbz/model"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_detectFormat(t *testing.T) {
+	tar := make([]byte, 512)
+	copy(tar[257:], "ustar\x0000")
+	tests := []struct {
+		name string
+		head []byte
+		want format
+	}{
+		{"RAR 4", []byte("Rar!\x1a\x07\x00"), formatRar},
+		{"RAR 5", []byte("Rar!\x1a\x07\x01\x00"), formatRar},
+		{"7z", []byte("7z\xbc\xaf\x27\x1c\x00\x04"), format7z},
+		{"ZIP", []byte("PK\x03\x04\x14\x00"), formatZip},
+		{"Empty ZIP", []byte("PK\x05\x06\x00\x00"), formatZip},
+		{"tar", tar, formatTar},
+		{"Other", []byte("<ComicInfo/>"), formatOther},
+		{"Empty", nil, formatOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectFormat(tt.head); got != tt.want {
+				t.Errorf("detectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_outputName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"dir/comic.cbr", "dir/comic.cbz"},
+		{"comic.CB7", "comic.cbz"},
+		{"comic.cbt", "comic.cbz"},
+		{"comic.rar", "comic.cbz"},
+		{"comic.7z", "comic.cbz"},
+		{"comic.tar", "comic.cbz"},
+		{"comic.zip", "comic.cbz"},
+		{"comic.cbz", "comic.cbz"},
+		{"Comic v1.5", "Comic v1.5.cbz"},
+		{"comic", "comic.cbz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outputName(tt.name); got != tt.want {
+				t.Errorf("outputName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_byteSize_Set(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    byteSize
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"1024", 1024, false},
+		{"2k", 2 << 10, false},
+		{"256M", 256 << 20, false},
+		{"16G", 16 << 30, false},
+		{"", 0, true},
+		{"M", 0, true},
+		{"-1", 0, true},
+		{"1T", 0, true},
+		{"99999999999G", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			var got byteSize
+			err := got.Set(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Set() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_importArchive(t *testing.T) {
+	var page bytes.Buffer
+	if err := png.Encode(&page, image.NewGray(image.Rect(0, 0, 2, 3))); err != nil {
+		t.Fatal(err)
+	}
+	files := []ziptest.Entry{
+		{Name: "Comic/01.png", Content: page.String()},
+		{Name: "Comic/02.png", Content: page.String()},
+		{Name: "Comic/ComicInfo.xml", Content: `<ComicInfo><Series>From XML</Series><AgeRating>PG-13</AgeRating></ComicInfo>`},
+		{Name: "Comic/Thumbs.db", Content: "junk"},
+	}
+	pageSize := int64(page.Len())
+
+	tests := []struct {
+		name         string
+		maxEntrySize int64
+		maxTotalSize int64
+		wantErr      bool
+	}{
+		{"No limits", 0, 0, false},
+		{"Within limits", pageSize, 2 * pageSize, false},
+		{"Entry too large", pageSize - 1, 0, true},
+		{"Total too large", 0, 2*pageSize - 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			input := filepath.Join(dir, "Saga 001.zip")
+			ziptest.Write(t, input, "", files...)
+
+			var out bytes.Buffer
+			c := &config{out: &out, maxEntrySize: byteSize(tt.maxEntrySize), maxTotalSize: byteSize(tt.maxTotalSize), comicInfo: true}
+			err := c.importArchive(input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("importArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			cbzName := filepath.Join(dir, "Saga 001.cbz")
+			if tt.wantErr {
+				if _, err = os.Stat(cbzName); !os.IsNotExist(err) {
+					t.Errorf("importArchive() wrote %s", cbzName)
+				}
+				return
+			}
+
+			r, err := zip.OpenReader(cbzName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			var names []string
+			for _, f := range r.File {
+				names = append(names, f.Name)
+			}
+			if want := []string{"Comic/01.png", "Comic/02.png", model.ComicInfoXmlName}; !reflect.DeepEqual(names, want) {
+				t.Errorf("importArchive() entries = %v, want %v", names, want)
+			}
+
+			info, err := model.ReadFS(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Series != "From XML" || info.Number != "1" || info.PageCount != 2 || info.AgeRating != "" {
+				t.Errorf("importArchive() ComicInfo = %+v", info)
+			}
+			wantPages := model.ArrayOfComicPageInfo{
+				{Image: 0, ImageSize: pageSize, ImageWidth: 2, ImageHeight: 3},
+				{Image: 1, ImageSize: pageSize, ImageWidth: 2, ImageHeight: 3},
+			}
+			if !reflect.DeepEqual(info.Pages, wantPages) {
+				t.Errorf("importArchive() Pages = %+v, want %+v", info.Pages, wantPages)
+			}
+		})
+	}
+}
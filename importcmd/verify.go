@@ -0,0 +1,158 @@
+package importcmd
+
+import (
+	"archive/zip"
+	"fmt"
+	"github.com/blissd/cbz/atomicfile"
+	"github.com/blissd/cbz/model"
+	"github.com/gen2brain/go-unarr"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// verify checks that a CBZ file holds every copied entry, and nothing else, with the size and checksum
+// computed as the entry was copied. Every entry is read, so the stored data is checked against the checksum too.
+// If comicInfo is not nil, the CBZ file must also hold a generated ComicInfo.xml file with that content.
+//
+// This checks the CBZ file was written and stored correctly, but not that the data copied from the imported archive
+// was read correctly, as the checksums are of the same bytes. compareSource reads the imported archive again for that.
+func verify(name string, entries []entry, comicInfo []byte) error {
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return fmt.Errorf("failed to verify CBZ file: %w", err)
+	}
+	defer r.Close()
+
+	files := map[string]*zip.File{}
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+
+	copied := 0
+	for _, e := range entries {
+		if e.skipped != "" {
+			continue
+		}
+		copied++
+
+		f, ok := files[e.target]
+		if !ok {
+			return fmt.Errorf("failed to verify CBZ file: %s is missing", e.target)
+		}
+		if f.UncompressedSize64 != uint64(e.size) || f.CRC32 != e.crc32 {
+			return fmt.Errorf("failed to verify CBZ file: %s doesn't match %s", e.target, e.name)
+		}
+		if err = readAll(f); err != nil {
+			return fmt.Errorf("failed to verify CBZ file: %w", err)
+		}
+	}
+
+	if comicInfo != nil {
+		copied++
+		f, ok := files[model.ComicInfoXmlName]
+		if !ok {
+			return fmt.Errorf("failed to verify CBZ file: %s is missing", model.ComicInfoXmlName)
+		}
+		if f.UncompressedSize64 != uint64(len(comicInfo)) || f.CRC32 != crc32.ChecksumIEEE(comicInfo) {
+			return fmt.Errorf("failed to verify CBZ file: %s doesn't match", model.ComicInfoXmlName)
+		}
+		if err = readAll(f); err != nil {
+			return fmt.Errorf("failed to verify CBZ file: %w", err)
+		}
+	}
+
+	if len(r.File) != copied || len(files) != copied {
+		return fmt.Errorf("failed to verify CBZ file: %d entries, expected %d", len(r.File), copied)
+	}
+	return nil
+}
+
+// compareSource checks that every copied entry of a CBZ file has the checksum of the entry it was copied from,
+// read again from the imported archive. Entries must be listed from the archive, in the same order.
+func compareSource(inputName, cbzName string, entries []entry) error {
+	input, err := unarr.NewArchive(inputName)
+	if err != nil {
+		return fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer input.Close()
+
+	r, err := zip.OpenReader(cbzName)
+	if err != nil {
+		return fmt.Errorf("failed to compare CBZ file: %w", err)
+	}
+	defer r.Close()
+
+	files := map[string]*zip.File{}
+	for _, f := range r.File {
+		files[f.Name] = f
+	}
+
+	for _, e := range entries {
+		if err = input.Entry(); err != nil {
+			return fmt.Errorf("failed moving to next archive entry: %w", err)
+		}
+		if e.skipped != "" {
+			continue
+		}
+
+		f, ok := files[e.target]
+		if !ok {
+			return fmt.Errorf("failed to compare CBZ file: %s is missing", e.target)
+		}
+		h := crc32.NewIEEE()
+		n, err := io.Copy(h, &entryReader{input: input, name: e.name, remaining: e.size})
+		if err != nil {
+			return fmt.Errorf("failed to compare CBZ file: %w", err)
+		}
+		if f.UncompressedSize64 != uint64(n) || f.CRC32 != h.Sum32() {
+			return fmt.Errorf("failed to compare CBZ file: %s doesn't match %s in the imported archive", e.target, e.name)
+		}
+	}
+	return nil
+}
+
+// readAll reads a ZIP entry, which checks the data against the checksum.
+func readAll(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	if _, err = io.Copy(io.Discard, rc); err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// moveFile moves a file into a folder, copying it if the folder is on another file system.
+// Returns the new name of the file. An existing file isn't overwritten.
+func moveFile(name, dir string) (string, error) {
+	target := filepath.Join(dir, filepath.Base(name))
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("%s already exists", target)
+	}
+
+	if err := os.Rename(name, target); err == nil {
+		return target, nil
+	}
+
+	// Rename fails across file systems
+	src, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	err = atomicfile.Write(target, name, func(w io.Writer) error {
+		_, err := io.Copy(w, src)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if err = src.Close(); err != nil {
+		return "", err
+	}
+	return target, os.Remove(name)
+}
@@ -0,0 +1,105 @@
+package importcmd
+
+import (
+	"github.com/blissd/cbz/internal/ziptest"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_verify(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "comic.cbz")
+	ziptest.Write(t, name, "",
+		ziptest.Entry{Name: "ch1/01.jpg", Content: "ch1/01.jpg"},
+		ziptest.Entry{Name: "ch1/02.jpg", Content: "ch1/02.jpg"},
+	)
+
+	copied := func(name string) entry {
+		return entry{name: name, target: name, size: int64(len(name)), crc32: crc32.ChecksumIEEE([]byte(name))}
+	}
+	tests := []struct {
+		name    string
+		entries []entry
+		wantErr bool
+	}{
+		{"Match", []entry{copied("ch1/01.jpg"), copied("ch1/02.jpg"), {name: "Thumbs.db", skipped: "junk"}}, false},
+		{"Missing", []entry{copied("ch1/01.jpg"), copied("ch1/02.jpg"), copied("ch1/03.jpg")}, true},
+		{"Extra", []entry{copied("ch1/01.jpg")}, true},
+		{"Checksum", []entry{copied("ch1/01.jpg"), {name: "ch1/02.jpg", target: "ch1/02.jpg", size: 10}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := verify(name, tt.entries, nil); (err != nil) != tt.wantErr {
+				t.Errorf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_compareSource(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "comic.zip")
+	ziptest.Write(t, input, "",
+		ziptest.Entry{Name: "Thumbs.db", Content: "junk"},
+		ziptest.Entry{Name: "ch1/01.jpg", Content: "page 1"},
+		ziptest.Entry{Name: "ch1/02.jpg", Content: "page 2"},
+	)
+
+	page1 := ziptest.Entry{Name: "01.jpg", Content: "page 1"}
+	tests := []struct {
+		name    string
+		files   []ziptest.Entry
+		wantErr bool
+	}{
+		{"Match", []ziptest.Entry{page1, {Name: "02.jpg", Content: "page 2"}}, false},
+		{"Changed", []ziptest.Entry{page1, {Name: "02.jpg", Content: "page X"}}, true},
+		{"Truncated", []ziptest.Entry{page1, {Name: "02.jpg", Content: "page"}}, true},
+		{"Missing", []ziptest.Entry{page1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cbzName := filepath.Join(t.TempDir(), "comic.cbz")
+			ziptest.Write(t, cbzName, "", tt.files...)
+			entries := []entry{
+				{name: "Thumbs.db", size: 4, skipped: "junk"},
+				{name: "ch1/01.jpg", size: 6, target: "01.jpg"},
+				{name: "ch1/02.jpg", size: 6, target: "02.jpg"},
+			}
+			if err := compareSource(input, cbzName, entries); (err != nil) != tt.wantErr {
+				t.Errorf("compareSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_moveFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "comic.cbr")
+	if err := os.WriteFile(name, []byte("rar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	done := filepath.Join(dir, "done")
+	if err := os.Mkdir(done, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := moveFile(name, done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(done, "comic.cbr"); target != want {
+		t.Errorf("moveFile() = %v, want %v", target, want)
+	}
+	if _, err = os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("moveFile() left %v", name)
+	}
+
+	// An existing file isn't overwritten
+	if err = os.WriteFile(name, []byte("rar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = moveFile(name, done); err == nil {
+		t.Errorf("moveFile() overwrote %v", target)
+	}
+}
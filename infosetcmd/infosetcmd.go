@@ -1,22 +1,18 @@
 package infosetcmd
 
 import (
-	"archive/zip"
+	"bytes"
 	"context"
-	"encoding/xml"
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/blissd/cbz/cbi"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/metron"
 	"github.com/blissd/cbz/model"
-	"github.com/chai2010/webp"
 	"github.com/peterbourgon/ff/v3/ffcli"
-	"image"
-	"image/jpeg"
-	"image/png"
 	"io"
 	"os"
-	"path/filepath"
-	"reflect"
-	"sort"
 	"strings"
 )
 
@@ -28,8 +24,43 @@ type config struct {
 
 	// inferDoublePages compute if a page is double width based on some simple heuristics
 	inferDoublePages bool
+
+	// schemaVersion is the ComicInfo.xml schema version to validate against, rejecting newer fields.
+	// It doesn't change the XML written. If blank, the version read is kept unless newer fields require an upgrade.
+	schemaVersion string
+
+	// cbiMode is how ComicBookInfo metadata in the ZIP comment is handled. One of the cbi* constants.
+	cbiMode string
+
+	// input is a path to an XML, JSON or YAML metadata document to apply, or "-" for stdin.
+	input string
+
+	// replace the existing metadata with the input document instead of merging it.
+	replace bool
+
+	// lenient reads malformed ComicInfo.xml files, normalising what it can.
+	lenient bool
+
+	// mergePolicy is how the input document is merged with existing metadata. See model.ParseMergePolicy.
+	mergePolicy string
+
+	// inPlace appends changes to the archive file instead of rewriting it. See comic.Archive.SaveInPlace.
+	inPlace bool
 }
 
+const (
+	// cbiKeep leaves the ZIP comment unchanged.
+	cbiKeep = "keep"
+
+	// cbiConvert creates ComicInfo.xml from ComicBookInfo if there is no ComicInfo.xml, or fills its empty fields
+	// from ComicBookInfo if there is, then removes the ComicBookInfo.
+	cbiConvert = "convert"
+
+	// cbiSync creates ComicInfo.xml from ComicBookInfo if there is no ComicInfo.xml, then rewrites the ComicBookInfo to match.
+	// ComicBookInfo is created if the ZIP comment is empty, but a comment that isn't ComicBookInfo is an error.
+	cbiSync = "sync"
+)
+
 // New creates a ffcli.Command for updating the metadata in a ComicInfo.xml file.
 // Can update multiple fields at once. Operates on multiple CBZ files sequentially.
 func New(out io.Writer) *ffcli.Command {
@@ -40,11 +71,18 @@ func New(out io.Writer) *ffcli.Command {
 	fs := flag.NewFlagSet("cbz set", flag.ExitOnError)
 	fs.BoolVar(&cfg.computePages, "p", false, "compute values for the 'pages' element")
 	fs.BoolVar(&cfg.inferDoublePages, "d", false, "infer double page spreads. Implies -p.")
+	fs.StringVar(&cfg.cbiMode, "cbi", cbiKeep, "ComicBookInfo handling: keep, convert (to ComicInfo.xml), or sync (with ComicInfo.xml)")
+	fs.StringVar(&cfg.schemaVersion, "schema", "", "ComicInfo.xml schema version to validate against, 2.0 or 2.1. Fields newer than the version are rejected; the XML written is the same. Defaults to the oldest version that holds all fields.")
+	fs.StringVar(&cfg.input, "i", "", "read metadata from an XML, JSON or YAML file, or - for stdin. Merged before field=value arguments are applied.")
+	fs.BoolVar(&cfg.replace, "r", false, "replace existing metadata with the -i document instead of merging")
+	fs.BoolVar(&cfg.lenient, "lenient", false, "read malformed ComicInfo.xml files, normalising values where possible")
+	fs.StringVar(&cfg.mergePolicy, "policy", model.MergeOverwrite.String(), "how the -i document is merged: fill (empty fields only), overwrite, or lists (add to multi-valued fields, fill others)")
+	fs.BoolVar(&cfg.inPlace, "inplace", false, "append changes to the archive instead of rewriting it. Faster for large archives; use 'cbz compact' to reclaim space.")
 
 	return &ffcli.Command{
 		Name:       "set",
-		ShortUsage: "cbz set <field=value> <comic.cbz>",
-		ShortHelp:  "Set an field value in ComicInfo.xml. e.g., cbz meta set AgeRating=M comic.cbz",
+		ShortUsage: "cbz set [-i file|-] [-r] <field=value|field+=entry|field-=entry|field~=old=new> <comic.cbz>",
+		ShortHelp:  "Set an field value in ComicInfo.xml, or MetronInfo.xml if there is no ComicInfo.xml. e.g., cbz meta set AgeRating=M comic.cbz",
 		FlagSet:    fs,
 		Exec:       cfg.exec,
 	}
@@ -53,6 +91,22 @@ func New(out io.Writer) *ffcli.Command {
 // exec is the callback for ffcli.Command
 func (c *config) exec(_ context.Context, args []string) error {
 
+	version, err := model.ParseSchemaVersion(c.schemaVersion)
+	if err != nil {
+		return fmt.Errorf("invalid schema version: %w", err)
+	}
+
+	policy, err := model.ParseMergePolicy(c.mergePolicy)
+	if err != nil {
+		return err
+	}
+
+	switch c.cbiMode {
+	case cbiKeep, cbiConvert, cbiSync:
+	default:
+		return fmt.Errorf("invalid ComicBookInfo mode: %v", c.cbiMode)
+	}
+
 	zipFileNames := []string{}
 
 	for _, v := range args {
@@ -64,33 +118,26 @@ func (c *config) exec(_ context.Context, args []string) error {
 	// remove file names from argument list so only metadata name=value pairs are left
 	args = args[:len(args)-len(zipFileNames)]
 
-	setActions := make([]comicInfoAction, len(args), len(args)+2) // leave space for Validate and (optional) printXml actions
+	setActions := make([]comicInfoAction, 0, len(args)+1)
 
-	for i, v := range args {
-		nameAndValue := strings.Split(v, "=")
-		if len(nameAndValue) != 2 {
-			return fmt.Errorf("malformed metadata: '%v'", v)
-		}
-		typedValue, err := model.Convert(nameAndValue[0], nameAndValue[1])
+	if c.input != "" {
+		doc, err := c.readInput()
 		if err != nil {
-			return fmt.Errorf("field %s has invalid value %s: %w", nameAndValue[0], nameAndValue[1], err)
+			return err
 		}
-		setActions[i] = setField(nameAndValue[0], typedValue)
+		setActions = append(setActions, loadInfo(doc, c.replace, policy))
 	}
 
-	for _, name := range zipFileNames {
-		actions := make([]comicInfoAction, 0, len(setActions)+3)
-		for _, a := range setActions {
-			actions = append(actions, a)
-		}
-
-		if c.inferDoublePages {
-			actions = append(actions, c.inferDoubles(name))
+	for _, v := range args {
+		action, err := parseAction(v)
+		if err != nil {
+			return err
 		}
+		setActions = append(setActions, action)
+	}
 
-		action := join(append(actions, validate, c.printXml)) // TODO only add this comicInfoAction with a -v "verbose" flag
-
-		err := c.updateZip(name, action)
+	for _, name := range zipFileNames {
+		err := c.updateZip(name, setActions, version)
 		if err != nil {
 			return fmt.Errorf("failed updating comic archive '%s': %w", name, err)
 		}
@@ -99,64 +146,101 @@ func (c *config) exec(_ context.Context, args []string) error {
 	return nil
 }
 
-// updateZip updates a single zip file to transform the ComicInfo.xml file.
-// Source file will be replaced with updated version.
-func (c *config) updateZip(zipFileName string, action comicInfoAction) error {
-
-	updatedZip, err := os.CreateTemp(filepath.Dir(zipFileName), filepath.Base(zipFileName))
+// updateZip updates the metadata of a single comic archive.
+// Source file will be replaced with updated version, or appended to if updating in place.
+func (c *config) updateZip(zipFileName string, setActions []comicInfoAction, version model.SchemaVersion) error {
+	archive, err := comic.Open(zipFileName)
 	if err != nil {
-		return fmt.Errorf("failed creating temporary file: %w", err)
+		return err
 	}
+	defer archive.Close()
 
-	err = c.applyActions(zipFileName, action, updatedZip)
+	actions := append([]comicInfoAction{}, setActions...)
+	if c.inferDoublePages {
+		actions = append(actions, archive.InferDoubles)
+	}
+	actions = append(actions, setVersion(version))
+
+	var changes []model.Change
+	err = c.applyActions(archive, actions, &changes)
 	if err != nil {
-		updatedZip.Close()
-		os.Remove(updatedZip.Name())
 		return fmt.Errorf("failed processing comic book archive: %w", err)
 	}
 
-	// Success, so replace original file with updated file.
-	updatedZip.Close()
-
-	err = os.Rename(updatedZip.Name(), zipFileName)
+	if c.inPlace {
+		err = archive.SaveInPlace()
+	} else {
+		err = archive.Save()
+	}
 	if err != nil {
-		return fmt.Errorf("failed moving file: %w", err)
+		return err
 	}
 
+	c.printChanges(zipFileName, changes)
 	return nil
 }
 
-// applyActions applies a series of actions to files in a zip archive.
-func (c *config) applyActions(zipFileName string, action comicInfoAction, output io.Writer) error {
-	input, err := zip.OpenReader(zipFileName)
-	if err != nil {
-		return fmt.Errorf("failed to open input file: %w", err)
+// applyActions applies a series of actions to the metadata of a comic archive, recording the changes they make.
+// Works on ComicInfo.xml if there is one, otherwise on MetronInfo.xml, otherwise on ComicBookInfo in the ZIP comment.
+// When ComicBookInfo is converted, it fills the empty fields of the existing metadata first, so none of it is lost.
+func (c *config) applyActions(archive *comic.Archive, actions []comicInfoAction, changes *[]model.Change) error {
+	// ComicBookInfo in the ZIP comment
+	comment := archive.Comment()
+	var cbiDoc *cbi.Document
+	var err error
+	if c.cbiMode != cbiKeep && cbi.IsComicBookInfo(comment) {
+		cbiDoc, err = cbi.Unmarshal(comment)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal ComicBookInfo: %w", err)
+		}
+	}
+	if c.cbiMode == cbiSync && cbiDoc == nil && comment != "" {
+		return fmt.Errorf("the ZIP comment is not ComicBookInfo, and would be replaced by -cbi %s: remove the comment first, or use -cbi %s", cbiSync, cbiKeep)
 	}
-	defer input.Close()
 
-	outputZip := zip.NewWriter(output)
-	defer outputZip.Close()
+	var info *model.ComicInfo
+	var metronInfo *metron.MetronInfo
+	var metronName string
 
-	// Will write the ComicInfo.xml file as the last entry of the CBZ archive
-	var info *model.ComicInfo = &model.ComicInfo{}
+	if c.lenient {
+		var warnings []model.Warning
+		info, warnings, err = archive.ComicInfoLenient()
+		for _, w := range warnings {
+			_, _ = fmt.Fprintf(c.out, "%s: warning: %v\n", archive.Path(), w)
+		}
+	} else {
+		info, err = archive.ComicInfo()
+	}
+	if err != nil && !errors.Is(err, model.ErrNotFound) {
+		return fmt.Errorf("failed to unmarshal ComicInfo.xml: %w", err)
+	}
 
-	for _, file := range input.File {
-		if file.Name == model.ComicInfoXmlName {
-			info, err = model.Unmarshal(file)
-			if err != nil {
-				return fmt.Errorf("failed to unmarshal ComicInfo.xml: %w", err)
+	if info == nil {
+		for _, file := range archive.Files() {
+			if metron.IsMetronInfo(file.Name) {
+				metronInfo, err = metron.Unmarshal(file)
+				if err != nil {
+					return fmt.Errorf("failed to unmarshal MetronInfo.xml: %w", err)
+				}
+				metronName = file.Name
+				info = metronInfo.ComicInfo()
+				break
 			}
-			continue // don't copy the ComicInfo.xml file as it will be processed and added last.
 		}
+	}
 
-		// Copies source file as-is. No-decompression/validation/re-compression.
-		err = outputZip.Copy(file)
-		if err != nil {
-			return fmt.Errorf("failed to add %s: %w", file.Name, err)
+	switch {
+	case info != nil:
+		if c.cbiMode == cbiConvert && cbiDoc != nil {
+			actions = append([]comicInfoAction{loadInfo(cbiDoc.Info.ComicInfo(), false, model.MergeFillEmpty)}, actions...)
 		}
+	case cbiDoc != nil:
+		info = cbiDoc.Info.ComicInfo()
+	default:
+		info = &model.ComicInfo{}
 	}
 
-	err = action(info)
+	err = recordChanges(join(actions), changes)(info)
 	if err != nil {
 		return fmt.Errorf("failed to apply comicInfoAction to ComicInfo.xml: %w", err)
 	}
@@ -166,96 +250,162 @@ func (c *config) applyActions(zipFileName string, action comicInfoAction, output
 		return fmt.Errorf("failed to produce a valid ComicInfo.xml: %w", err)
 	}
 
-	bs, err := xml.MarshalIndent(info, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal ComicInfo.xml: %w", err)
+	if metronInfo != nil {
+		if unmapped := metronInfo.Update(info); len(unmapped) > 0 {
+			return fmt.Errorf("MetronInfo.xml can't hold the changes to %s", strings.Join(unmapped, ", "))
+		}
+		bs, err := model.MarshalDocument(metronInfo)
+		if err != nil {
+			return fmt.Errorf("failed to marshal MetronInfo.xml: %w", err)
+		}
+		archive.SetFile(metronName, bs)
+	} else if err = archive.SetComicInfo(info); err != nil {
+		return err
 	}
-	w, err := outputZip.Create(model.ComicInfoXmlName)
-	if _, err = w.Write(bs); err != nil {
-		return fmt.Errorf("failed to write ComicInfo.xml: %w", err)
+
+	switch {
+	case c.cbiMode == cbiConvert && cbiDoc != nil:
+		archive.SetComment("")
+	case c.cbiMode == cbiSync:
+		if cbiDoc == nil {
+			// Only an empty comment, checked above, is replaced by new ComicBookInfo
+			cbiDoc = &cbi.Document{}
+		}
+		cbiDoc.Update(info)
+		comment, err = cbiDoc.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to marshal ComicBookInfo: %w", err)
+		}
+		archive.SetComment(comment)
 	}
 
 	return nil
 }
 
-// comicInfoAction performs an comicInfoAction on a ComicInfo, such as printing a value, setting a value, or removing a value.
-type comicInfoAction func(info *model.ComicInfo) error
+// readInput reads the metadata document named by the -i flag, detecting its format from the content.
+func (c *config) readInput() (*model.ComicInfo, error) {
+	var bs []byte
+	var err error
+	if c.input == "-" {
+		bs, err = io.ReadAll(os.Stdin)
+	} else {
+		bs, err = os.ReadFile(c.input)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata input: %w", err)
+	}
 
-// printXml is an comicInfoAction that prints the ComicInfo.xml to stdout.
-func (c *config) printXml(info *model.ComicInfo) error {
-	_, _ = fmt.Fprintln(c.out, info)
-	return nil
+	doc, err := model.Decode(bytes.NewReader(bs), model.DetectFormat(bs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata input %s: %w", c.input, err)
+	}
+	return doc, nil
 }
 
-// validate is an comicInfoAction that validates a ComicInfo.xml.
-func validate(info *model.ComicInfo) error {
-	return info.Validate()
-}
+// parseAction parses a command line argument into a comicInfoAction.
+// "field=value" sets a field. For multi-valued fields "field+=entry" adds an entry,
+// "field-=entry" removes an entry, and "field~=old=new" replaces an entry.
+func parseAction(arg string) (comicInfoAction, error) {
+	name, value, ok := strings.Cut(arg, "=")
+	if !ok {
+		return nil, fmt.Errorf("malformed metadata: '%v'", arg)
+	}
 
-func (cfg *config) inferDoubles(zipFileName string) comicInfoAction {
-	return func(info *model.ComicInfo) error {
-		input, err := zip.OpenReader(zipFileName)
-		if err != nil {
-			return fmt.Errorf("failed to open input file: %w", err)
-		}
-		defer input.Close()
+	var op byte
+	if n := len(name); n > 0 && strings.IndexByte("+-~", name[n-1]) >= 0 {
+		op = name[n-1]
+		name = name[:n-1]
+	}
 
-		var pageCount int
-		for _, file := range input.File {
-			if isImage(file.Name) {
-				pageCount++
-			}
-		}
+	field, err := model.LookupField(name)
+	if err != nil {
+		return nil, fmt.Errorf("malformed metadata: '%v': %w", arg, err)
+	}
 
-		if pageCount == 0 {
-			return fmt.Errorf("no pages in comic archive")
-		}
+	if op != 0 && !field.Multi {
+		return nil, fmt.Errorf("malformed metadata: '%v': field %s is not multi-valued", arg, field.Name)
+	}
 
-		pages := make([]model.ComicPageInfo, pageCount, pageCount)
+	switch op {
+	case '+':
+		return editList(field, func(l model.List) (model.List, error) {
+			return l.Add(value), nil
+		}), nil
+	case '-':
+		return editList(field, func(l model.List) (model.List, error) {
+			return l.Remove(value)
+		}), nil
+	case '~':
+		old, replacement, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("malformed metadata: '%v': expected %s~=old=new", arg, field.Name)
+		}
+		return editList(field, func(l model.List) (model.List, error) {
+			return l.Replace(old, replacement)
+		}), nil
+	}
 
-		// copy data from any existing pages
-		pageIndex := 0
-		for _, file := range input.File {
-			if !isImage(file.Name) {
-				continue
-			}
+	typedValue, err := field.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("field %s has invalid value %s: %w", field.Name, value, err)
+	}
+	return setField(field.Name, typedValue), nil
+}
 
-			if pageIndex < len(info.Pages) {
-				pages[pageIndex] = info.Pages[pageIndex]
-			}
-			pages[pageIndex].Image = pageIndex
-			_, err = cfg.updatePage(&pages[pageIndex], file)
-			if err != nil {
-				return fmt.Errorf("failed updating page: %w", err)
-			}
-			pageIndex++
-		}
+// comicInfoAction performs an comicInfoAction on a ComicInfo, such as printing a value, setting a value, or removing a value.
+type comicInfoAction func(info *model.ComicInfo) error
 
-		// compute median average page width and a range with tolerance for double page width
-		widths := make([]int, len(pages), len(pages))
-		for i, p := range pages {
-			widths[i] = p.ImageWidth
+// recordChanges wraps a comicInfoAction to record the changes it makes.
+func recordChanges(action comicInfoAction, changes *[]model.Change) comicInfoAction {
+	return func(info *model.ComicInfo) error {
+		before := info.Clone()
+		if err := action(info); err != nil {
+			return err
 		}
+		*changes = before.Diff(info)
+		return nil
+	}
+}
 
-		sort.Ints(widths)
-		middle := len(widths) / 2
-		doublePageWidth := widths[middle] * 2
-
-		loDoublePageWidth, hiDoublePageWidth := int(float64(doublePageWidth)*0.8), int(float64(doublePageWidth)*1.2)
+// printChanges prints the changes made to the metadata of a comic archive, once they are saved.
+func (c *config) printChanges(zipFileName string, changes []model.Change) {
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(c.out, "%s: no changes\n", zipFileName)
+		return
+	}
+	_, _ = fmt.Fprintf(c.out, "%s:\n", zipFileName)
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(c.out, "  %v\n", change)
+	}
+}
 
-		fmt.Println("lo/mid/hi", loDoublePageWidth, "/", doublePageWidth, "/", hiDoublePageWidth)
+// setVersion is an comicInfoAction that sets the schema version to validate against.
+// A blank version keeps the version read, upgrading it if populated fields need a newer version.
+func setVersion(version model.SchemaVersion) comicInfoAction {
+	return func(info *model.ComicInfo) error {
+		if version != "" {
+			info.Version = version
+		} else if info.Version.Before(info.RequiredVersion()) {
+			info.Version = info.RequiredVersion()
+		}
+		return nil
+	}
+}
 
-		for i, _ := range pages {
-			if pages[i].ImageWidth >= loDoublePageWidth && pages[i].ImageWidth <= hiDoublePageWidth {
-				pages[i].DoublePage = true
-				//fmt.Println(pages[i])
-				fmt.Printf("Computed double page for page %d with width=%d and height=%d\n", i, pages[i].ImageWidth, pages[i].ImageHeight)
+// loadInfo is an comicInfoAction that applies a metadata document.
+// With replace the document replaces all existing metadata, otherwise it is merged with the policy.
+// Attributes of the ComicInfo element, such as namespace declarations, are kept unless the document has its own.
+func loadInfo(doc *model.ComicInfo, replace bool, policy model.MergePolicy) comicInfoAction {
+	return func(info *model.ComicInfo) error {
+		if replace {
+			attrs := info.Attrs
+			*info = *doc.Clone()
+			if len(info.Attrs) == 0 {
+				info.Attrs = attrs
 			}
+			return nil
 		}
-
-		info.PageCount = int64(pageCount)
-		info.Pages = pages
-		return nil
+		return info.Merge(doc, policy)
 	}
 }
 
@@ -272,84 +422,26 @@ func join(actions []comicInfoAction) comicInfoAction {
 }
 
 // setField overwrites the value of a named field in a ComicInfo.
-// Uses reflection... like a monster.
 func setField(name string, value any) comicInfoAction {
 	return func(info *model.ComicInfo) error {
-		rv := reflect.Indirect(reflect.ValueOf(info))
-		f := rv.FieldByName(name)
-		switch v := value.(type) {
-		case string:
-			f.SetString(v)
-		case int64:
-			f.SetInt(v)
-		case float64:
-			f.SetFloat(v)
-		case bool:
-			f.SetBool(v)
-		default:
-			return fmt.Errorf("field %s has unsupported data type: %v", name, v)
+		field, err := model.LookupField(name)
+		if err != nil {
+			return err
 		}
-		return nil
-	}
-}
-
-func (c *config) updatePage(page *model.ComicPageInfo, file *zip.File) (*model.ComicPageInfo, error) {
-	ir, err := file.Open()
-	if err != nil {
-		return page, fmt.Errorf("failed to open image file '%v': %w", file.Name, err)
-	}
-	defer ir.Close()
-
-	var img image.Image = nil
-
-	switch {
-	case isJpeg(file.Name):
-		img, err = jpeg.Decode(ir)
-	case isPng(file.Name):
-		img, err = png.Decode(ir)
-	case isWebp(file.Name):
-		img, err = webp.Decode(ir)
-	}
-
-	if err != nil {
-		return page, fmt.Errorf("failed to decode image '%v': %w", file.Name, err)
-	}
-
-	if img == nil {
-		return page, fmt.Errorf("nil decode for image '%v'", file.Name)
+		return field.Set(info, value)
 	}
-
-	page.ImageWidth = img.Bounds().Dx()
-	page.ImageHeight = img.Bounds().Dy()
-
-	return page, nil
-}
-func isImage(fileName string) bool {
-	return isJpeg(fileName) || isPng(fileName) || isWebp(fileName)
-}
-
-func isJpeg(fileName string) bool {
-	switch {
-	case strings.HasSuffix(fileName, ".jpg"):
-		return true
-	case strings.HasSuffix(fileName, ".jpeg"):
-		return true
-	}
-	return false
-}
-
-func isPng(fileName string) bool {
-	switch {
-	case strings.HasSuffix(fileName, ".png"):
-		return true
-	}
-	return false
 }
 
-func isWebp(fileName string) bool {
-	switch {
-	case strings.HasSuffix(fileName, ".webp"):
-		return true
+// editList is an comicInfoAction that changes the entries of a multi-valued field.
+func editList(field *model.Field, edit func(l model.List) (model.List, error)) comicInfoAction {
+	return func(info *model.ComicInfo) error {
+		l, err := field.List(info)
+		if err != nil {
+			return err
+		}
+		if l, err = edit(l); err != nil {
+			return fmt.Errorf("failed editing %s: %w", field.Name, err)
+		}
+		return field.SetList(info, l)
 	}
-	return false
 }
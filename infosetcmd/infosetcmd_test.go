@@ -1,7 +1,11 @@
 package infosetcmd
 
 import (
+	"bytes"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/internal/ziptest"
 	"github.com/blissd/cbz/model"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -38,3 +42,140 @@ func Test_setField(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		info    model.ComicInfo
+		want    model.ComicInfo
+		wantErr bool
+	}{
+		{"Set", "Volume=2", model.ComicInfo{}, model.ComicInfo{Volume: 2}, false},
+		{"Set with =", "Summary=a=b", model.ComicInfo{}, model.ComicInfo{Summary: "a=b"}, false},
+		{"Add", "Characters+=Alfred", model.ComicInfo{Characters: "Batman, Robin"}, model.ComicInfo{Characters: "Batman, Robin, Alfred"}, false},
+		{"Add duplicate", "Characters+=robin", model.ComicInfo{Characters: "Batman, Robin"}, model.ComicInfo{Characters: "Batman, Robin"}, false},
+		{"Remove", "Characters-=Robin", model.ComicInfo{Characters: "Batman, Robin"}, model.ComicInfo{Characters: "Batman"}, false},
+		{"Replace", "Characters~=Robin=Nightwing", model.ComicInfo{Characters: "Batman, Robin"}, model.ComicInfo{Characters: "Batman, Nightwing"}, false},
+		{"Not multi-valued", "Volume+=2", model.ComicInfo{}, model.ComicInfo{}, true},
+		{"Unknown field", "Charcters+=Alfred", model.ComicInfo{}, model.ComicInfo{}, true},
+		{"Malformed replace", "Characters~=Robin", model.ComicInfo{}, model.ComicInfo{}, true},
+		{"Malformed", "Volume", model.ComicInfo{}, model.ComicInfo{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, err := parseAction(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			info := tt.info
+			if err = action(&info); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(info, tt.want) {
+				t.Errorf("parseAction() = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
+
+func Test_loadInfo(t *testing.T) {
+	existing := func() model.ComicInfo {
+		return model.ComicInfo{Title: "Old", Series: "Saga", Pages: []model.ComicPageInfo{{Image: 0}}}
+	}
+	doc := &model.ComicInfo{Title: "New", Volume: 2}
+
+	info := existing()
+	if err := loadInfo(doc, false, model.MergeOverwrite)(&info); err != nil {
+		t.Fatal(err)
+	}
+	want := model.ComicInfo{Title: "New", Series: "Saga", Volume: 2, Pages: []model.ComicPageInfo{{Image: 0}}}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("loadInfo() merge = %v, want %v", info, want)
+	}
+
+	info = existing()
+	if err := loadInfo(doc, true, model.MergeOverwrite)(&info); err != nil {
+		t.Fatal(err)
+	}
+	want = model.ComicInfo{Title: "New", Volume: 2}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("loadInfo() replace = %v, want %v", info, want)
+	}
+}
+
+func Test_setVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     model.SchemaVersion
+		info        model.ComicInfo
+		wantVersion model.SchemaVersion
+		wantErr     bool
+	}{
+		{"Keep", "", model.ComicInfo{Title: "T", Version: model.SchemaVersion21}, model.SchemaVersion21, false},
+		{"Upgrade", "", model.ComicInfo{GTIN: "9781534300255", Version: model.SchemaVersion20}, model.SchemaVersion21, false},
+		{"Set", model.SchemaVersion20, model.ComicInfo{Title: "T"}, model.SchemaVersion20, false},
+		{"Reject newer fields", model.SchemaVersion20, model.ComicInfo{GTIN: "9781534300255"}, model.SchemaVersion20, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			if err := setVersion(tt.version)(&info); err != nil {
+				t.Fatal(err)
+			}
+			if info.Version != tt.wantVersion {
+				t.Errorf("setVersion() Version = %v, want %v", info.Version, tt.wantVersion)
+			}
+			if err := info.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_config_updateZip_cbiWithComicInfo(t *testing.T) {
+	const comment = `{"appID": "ComicTagger/1.0.0", "ComicBookInfo/1.0": {"series": "From CBI", "rating": 4.25, ` +
+		`"credits": [{"person": "Alan Moore", "role": "Writer", "primary": true}], "tags": ["Vigilantes"]}}`
+
+	tests := []struct {
+		name        string
+		cbiMode     string
+		want        model.ComicInfo
+		wantComment string
+	}{
+		{"Keep", cbiKeep, model.ComicInfo{Series: "From XML", Volume: 2}, comment},
+		{"Convert", cbiConvert, model.ComicInfo{Series: "From XML", Volume: 2, Writer: "Alan Moore", Tags: "Vigilantes", CommunityRating: 4.3}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "comic.cbz")
+			ziptest.Write(t, name, comment, ziptest.Entry{Name: model.ComicInfoXmlName, Content: `<ComicInfo><Series>From XML</Series></ComicInfo>`})
+
+			var out bytes.Buffer
+			c := &config{out: &out, cbiMode: tt.cbiMode}
+			if err := c.updateZip(name, []comicInfoAction{setField("Volume", int64(2))}, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			archive, err := comic.Open(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer archive.Close()
+			info, err := archive.ComicInfo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			info.Version = ""
+			if !reflect.DeepEqual(*info, tt.want) {
+				t.Errorf("updateZip() ComicInfo = %+v, want %+v", *info, tt.want)
+			}
+			if got := archive.Comment(); got != tt.wantComment {
+				t.Errorf("updateZip() comment = %q, want %q", got, tt.wantComment)
+			}
+		})
+	}
+}
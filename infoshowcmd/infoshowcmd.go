@@ -3,14 +3,26 @@ package infoshowcmd
 import (
 	"archive/zip"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"github.com/blissd/cbz/cbi"
+	"github.com/blissd/cbz/comic"
+	"github.com/blissd/cbz/metron"
 	"github.com/blissd/cbz/model"
 	"github.com/peterbourgon/ff/v3/ffcli"
 	"io"
+	"os"
 )
 
 type config struct {
 	out io.Writer
+
+	// err receives warnings, so they are kept apart from the metadata written to out.
+	err io.Writer
+
+	// format is the output format: xml, json or yaml.
+	format string
 }
 
 // New creates a new ffcli.Command for showing a ComicInfo.xml file.
@@ -18,35 +30,72 @@ type config struct {
 func New(out io.Writer) *ffcli.Command {
 	c := config{
 		out: out,
+		err: os.Stderr,
 	}
+	fs := flag.NewFlagSet("cbz show", flag.ExitOnError)
+	fs.StringVar(&c.format, "format", string(model.FormatXML), "output format: xml, json or yaml. MetronInfo.xml is converted to ComicInfo for json and yaml.")
 
 	return &ffcli.Command{
 		Name:       "show",
-		ShortUsage: "cbz show <comic.cbz>",
-		ShortHelp:  "Show the raw ComicInfo.xml file in a comic archive",
+		ShortUsage: "cbz show [-format xml|json|yaml] <comic.cbz>",
+		ShortHelp:  "Show the ComicInfo.xml file in a comic archive, or its MetronInfo.xml or ComicBookInfo if there is no ComicInfo.xml. Malformed ComicInfo.xml values are normalised with a warning.",
+		FlagSet:    fs,
 		Exec:       c.exec,
 	}
 }
 
 // exec is the callback for ffcli.Command
 func (c *config) exec(ctx context.Context, args []string) error {
-	input, err := zip.OpenReader(args[0])
+	format, err := model.ParseFormat(c.format)
 	if err != nil {
-		return fmt.Errorf("failed to open file:%w", err)
+		return err
+	}
+
+	archive, err := comic.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	info, warnings, err := archive.ComicInfoLenient()
+	switch {
+	case err == nil:
+		for _, w := range warnings {
+			_, _ = fmt.Fprintf(c.err, "%s: warning: %v\n", archive.Path(), w)
+		}
+		return model.Encode(c.out, info, format) // early
+	case !errors.Is(err, model.ErrNotFound):
+		return fmt.Errorf("failed to unmarshal ComicInfo.xml: %w", err)
 	}
-	defer input.Close()
 
-	for _, file := range input.File {
-		if file.Name == model.ComicInfoXmlName {
-			info, err := model.Unmarshal(file)
-			if err != nil {
-				return fmt.Errorf("failed to unmarshal ComicInfo.xml: %w", err)
-			}
+	var metronFile *zip.File
+	for _, file := range archive.Files() {
+		if metron.IsMetronInfo(file.Name) {
+			metronFile = file
+			break
+		}
+	}
 
+	if metronFile != nil {
+		info, err := metron.Unmarshal(metronFile)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal MetronInfo.xml: %w", err)
+		}
+		if format == model.FormatXML {
 			fmt.Fprintln(c.out, info)
-			return nil // early
+			return nil
+		}
+		return model.Encode(c.out, info.ComicInfo(), format)
+	}
+
+	// Fall back to ComicBookInfo metadata in the ZIP comment
+	if cbi.IsComicBookInfo(archive.Comment()) {
+		doc, err := cbi.Unmarshal(archive.Comment())
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal ComicBookInfo: %w", err)
 		}
+		return model.Encode(c.out, doc.Info.ComicInfo(), format)
 	}
 
-	return fmt.Errorf("no ComicInfo.xml file found")
+	return fmt.Errorf("no ComicInfo.xml or MetronInfo.xml file or ComicBookInfo found")
 }
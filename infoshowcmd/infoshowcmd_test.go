@@ -0,0 +1,54 @@
+package infoshowcmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/blissd/cbz/internal/ziptest"
+	"github.com/blissd/cbz/model"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_config_exec(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		xml     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			"Valid",
+			"yaml",
+			`<ComicInfo><Title>Title</Title></ComicInfo>`,
+			"Title: Title\n",
+			"",
+		},
+		{
+			"Malformed",
+			"json",
+			"\xef\xbb\xbf<ComicInfo><Title>Title</Title></ComicInfo>",
+			"{\n  \"Title\": \"Title\"\n}\n",
+			"warning: removed UTF-8 byte order mark\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "comic.cbz")
+			ziptest.Write(t, name, "", ziptest.Entry{Name: model.ComicInfoXmlName, Content: tt.xml})
+
+			var out, errOut bytes.Buffer
+			c := &config{out: &out, err: &errOut, format: tt.format}
+			if err := c.exec(context.Background(), []string{name}); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("exec() output = %q, want %q", out.String(), tt.wantOut)
+			}
+			if tt.wantErr == "" && errOut.Len() > 0 || !strings.HasSuffix(errOut.String(), tt.wantErr) {
+				t.Errorf("exec() warnings = %q, want %q", errOut.String(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package ziptest writes ZIP archives used as test fixtures.
+package ziptest
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"testing"
+)
+
+// Entry is a file in a ZIP archive.
+type Entry struct {
+	Name    string
+	Content string
+}
+
+// Bytes returns a ZIP archive holding the entries, in order, and the comment.
+func Bytes(t testing.TB, comment string, entries ...Entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, e := range entries {
+		fw, err := w.Create(e.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = fw.Write([]byte(e.Content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.SetComment(comment); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Write writes a ZIP archive holding the entries, in order, and the comment to a file.
+func Write(t testing.TB, name string, comment string, entries ...Entry) {
+	t.Helper()
+	if err := os.WriteFile(name, Bytes(t, comment, entries...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// File returns the only entry of an in-memory ZIP archive holding a single file.
+func File(t testing.TB, name string, content string) *zip.File {
+	t.Helper()
+	bs := Bytes(t, "", Entry{Name: name, Content: content})
+	r, err := zip.NewReader(bytes.NewReader(bs), int64(len(bs)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r.File[0]
+}
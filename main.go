@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"flag"
-	"github.com/blissd/cbz/cbrimportcmd"
+	"github.com/blissd/cbz/cometimportcmd"
+	"github.com/blissd/cbz/compactcmd"
+	"github.com/blissd/cbz/importcmd"
 	"github.com/blissd/cbz/infosetcmd"
 	"github.com/blissd/cbz/infoshowcmd"
 	"github.com/blissd/cbz/renamecmd"
@@ -19,8 +21,10 @@ func main() {
 		Subcommands: []*ffcli.Command{
 			infoshowcmd.New(os.Stdout),
 			infosetcmd.New(os.Stdout),
-			cbrimportcmd.New(os.Stdout),
+			importcmd.New(os.Stdout),
 			renamecmd.New(os.Stdout),
+			cometimportcmd.New(os.Stdout),
+			compactcmd.New(os.Stdout),
 		},
 		Exec: func(ctx context.Context, args []string) error {
 			return flag.ErrHelp
@@ -0,0 +1,462 @@
+package metron
+
+import (
+	"fmt"
+	"github.com/blissd/cbz/model"
+	"reflect"
+	"strings"
+)
+
+// Mapping between MetronInfo and ComicInfo is lossy in both directions.
+//
+// MetronInfo to ComicInfo drops database IDs, prices, the store date, universes, reprints,
+// the series start year and volume count, all but the first alternative series name,
+// and credits with roles that have no ComicInfo field, such as "Designer".
+// Credit roles are merged into the nearest ComicInfo field, so "Script" and "Plot" both become Writer.
+// Arc numbers are only kept if every arc has one.
+//
+// ComicInfo to MetronInfo drops fields that have no MetronInfo element: AlternateSeries, AlternateNumber,
+// AlternateCount, BlackAndWhite, Manga, ScanInformation, SeriesGroup, CommunityRating, MainCharacterOrTeam,
+// Review and TitleSort. A partial date is padded to a full CoverDate, so 2021-03 becomes 2021-03-01.
+// AgeRating values are mapped to the nearest of MetronInfo's coarser ratings.
+
+// creditFields maps ComicInfo credit fields to the MetronInfo role written for them.
+var creditFields = []struct {
+	field string
+	role  string
+}{
+	{"Writer", "Writer"},
+	{"Penciller", "Penciller"},
+	{"Inker", "Inker"},
+	{"Colorist", "Colorist"},
+	{"Letterer", "Letterer"},
+	{"CoverArtist", "Cover"},
+	{"Editor", "Editor"},
+	{"Translator", "Translator"},
+}
+
+// roleFields maps lower case MetronInfo roles to ComicInfo fields. Roles not listed have no equivalent.
+var roleFields = map[string]string{
+	"writer":             "Writer",
+	"script":             "Writer",
+	"story":              "Writer",
+	"plot":               "Writer",
+	"artist":             "Penciller",
+	"penciller":          "Penciller",
+	"breakdowns":         "Penciller",
+	"illustrator":        "Penciller",
+	"layouts":            "Penciller",
+	"inker":              "Inker",
+	"embellisher":        "Inker",
+	"finishes":           "Inker",
+	"ink assists":        "Inker",
+	"colorist":           "Colorist",
+	"color separations":  "Colorist",
+	"color assists":      "Colorist",
+	"color flats":        "Colorist",
+	"letterer":           "Letterer",
+	"cover":              "CoverArtist",
+	"editor":             "Editor",
+	"consulting editor":  "Editor",
+	"assistant editor":   "Editor",
+	"associate editor":   "Editor",
+	"group editor":       "Editor",
+	"senior editor":      "Editor",
+	"managing editor":    "Editor",
+	"collection editor":  "Editor",
+	"supervising editor": "Editor",
+	"executive editor":   "Editor",
+	"editor in chief":    "Editor",
+	"translator":         "Translator",
+}
+
+// ageRatings maps MetronInfo age ratings to ComicInfo age ratings.
+var ageRatings = map[string]model.AgeRating{
+	"Unknown":   "Unknown",
+	"Everyone":  "Everyone",
+	"Teen":      "Teen",
+	"Teen Plus": "MA15+",
+	"Mature":    "Mature 17+",
+	"Explicit":  "X18+",
+	"Adult":     "Adults Only 18+",
+}
+
+// metronAgeRatings maps ComicInfo age ratings to the nearest MetronInfo age rating.
+var metronAgeRatings = map[model.AgeRating]string{
+	"Unknown":         "Unknown",
+	"Rating Pending":  "Unknown",
+	"Early Childhood": "Everyone",
+	"Everyone":        "Everyone",
+	"G":               "Everyone",
+	"Everyone 10+":    "Everyone",
+	"PG":              "Everyone",
+	"Kids to Adults":  "Everyone",
+	"Teen":            "Teen",
+	"MA15+":           "Teen Plus",
+	"Mature 17+":      "Mature",
+	"M":               "Mature",
+	"R18+":            "Adult",
+	"Adults Only 18+": "Adult",
+	"X18+":            "Explicit",
+}
+
+// storySeparator separates story titles when they are combined into a ComicInfo Title.
+const storySeparator = "; "
+
+// ComicInfo maps MetronInfo to ComicInfo.
+func (m *MetronInfo) ComicInfo() *model.ComicInfo {
+	info := model.ComicInfo{
+		Number:     m.Number,
+		Summary:    m.Summary,
+		Notes:      m.Notes,
+		PageCount:  m.PageCount,
+		Genre:      values(m.Genres).String(),
+		Tags:       values(m.Tags).String(),
+		Characters: values(m.Characters).String(),
+		Teams:      values(m.Teams).String(),
+		Locations:  values(m.Locations).String(),
+		AgeRating:  ageRatings[m.AgeRating],
+		Pages:      append(model.ArrayOfComicPageInfo(nil), m.Pages...),
+	}
+
+	if m.Publisher != nil {
+		info.Publisher = m.Publisher.Name
+		if m.Publisher.Imprint != nil {
+			info.Imprint = m.Publisher.Imprint.Value
+		}
+	}
+
+	if s := m.Series; s != nil {
+		info.Series = s.Name
+		info.SeriesSort = s.SortName
+		info.Volume = s.Volume
+		info.Format = s.Format
+		info.Count = s.IssueCount
+		info.LanguageISO = s.Lang
+		if len(s.AlternativeNames) > 0 {
+			info.LocalizedSeries = s.AlternativeNames[0].Value
+		}
+	}
+
+	if len(m.Stories) > 0 {
+		var stories []string
+		for _, s := range m.Stories {
+			stories = append(stories, s.Value)
+		}
+		info.Title = strings.Join(stories, storySeparator)
+	} else {
+		info.Title = m.CollectionTitle
+	}
+
+	if len(m.CoverDate) >= len("2006-01-02") {
+		_, _ = fmt.Sscanf(m.CoverDate, "%d-%d-%d", &info.Year, &info.Month, &info.Day)
+	}
+
+	var arcs, numbers []string
+	for _, a := range m.Arcs {
+		arcs = append(arcs, a.Name)
+		numbers = append(numbers, a.Number)
+	}
+	info.StoryArc = model.List(arcs).String()
+	if len(numbers) > 0 && !contains(numbers, "") {
+		info.StoryArcNumber = strings.Join(numbers, ", ")
+	}
+
+	if m.GTIN != nil {
+		info.GTIN = m.GTIN.ISBN
+		if info.GTIN == "" {
+			info.GTIN = m.GTIN.UPC
+		}
+	}
+
+	var web model.List
+	for _, u := range m.URLs {
+		if u.Primary {
+			web = append(model.List{u.Value}, web...)
+		} else {
+			web = append(web, u.Value)
+		}
+	}
+	_ = info.SetList("Web", web)
+
+	for _, c := range m.Credits {
+		for _, r := range c.Roles {
+			if field, ok := roleFields[strings.ToLower(strings.TrimSpace(r.Value))]; ok {
+				l, _ := info.List(field)
+				_ = info.SetList(field, l.Add(c.Creator.Value))
+			}
+		}
+	}
+
+	info.Version = info.RequiredVersion()
+	return &info
+}
+
+// FromComicInfo creates a new MetronInfo from a ComicInfo.
+// Also returns the names of populated ComicInfo fields that MetronInfo can't hold, as Update does.
+func FromComicInfo(info *model.ComicInfo) (*MetronInfo, []string) {
+	m := MetronInfo{}
+	unmapped := m.Update(info)
+	return &m, unmapped
+}
+
+// Update changes the MetronInfo to match a ComicInfo.
+// Only elements whose ComicInfo equivalent has changed are updated, so database IDs and
+// other data ComicInfo can't hold are kept for everything else.
+// Returns the names of changed ComicInfo fields that MetronInfo can't hold as they are,
+// such as BlackAndWhite, or an AgeRating that maps to a coarser MetronInfo rating.
+func (m *MetronInfo) Update(info *model.ComicInfo) []string {
+	current := m.ComicInfo()
+
+	changed := func(fields ...string) bool {
+		for _, name := range fields {
+			f, _ := model.LookupField(name)
+			if !reflect.DeepEqual(f.Get(current), f.Get(info)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if changed("Number") {
+		m.Number = info.Number
+	}
+	if changed("Summary") {
+		m.Summary = info.Summary
+	}
+	if changed("Notes") {
+		m.Notes = info.Notes
+	}
+	if changed("PageCount") {
+		m.PageCount = info.PageCount
+	}
+	if changed("Genre") {
+		m.Genres = updateResources(m.Genres, model.ParseList(info.Genre))
+	}
+	if changed("Tags") {
+		m.Tags = updateResources(m.Tags, model.ParseList(info.Tags))
+	}
+	if changed("Characters") {
+		m.Characters = updateResources(m.Characters, model.ParseList(info.Characters))
+	}
+	if changed("Teams") {
+		m.Teams = updateResources(m.Teams, model.ParseList(info.Teams))
+	}
+	if changed("Locations") {
+		m.Locations = updateResources(m.Locations, model.ParseList(info.Locations))
+	}
+	if changed("AgeRating") {
+		m.AgeRating = metronAgeRatings[info.AgeRating]
+	}
+	if !reflect.DeepEqual(current.Pages, info.Pages) {
+		m.Pages = append(model.ArrayOfComicPageInfo(nil), info.Pages...)
+	}
+
+	if changed("Publisher", "Imprint") {
+		if m.Publisher == nil {
+			m.Publisher = &Publisher{}
+		}
+		m.Publisher.Name = info.Publisher
+		if info.Imprint == "" {
+			m.Publisher.Imprint = nil
+		} else if m.Publisher.Imprint == nil || m.Publisher.Imprint.Value != info.Imprint {
+			m.Publisher.Imprint = &Imprint{Value: info.Imprint}
+		}
+		if info.Publisher == "" && info.Imprint == "" {
+			m.Publisher = nil
+		}
+	}
+
+	if changed("Series", "SeriesSort", "Volume", "Format", "Count", "LanguageISO", "LocalizedSeries") {
+		if m.Series == nil {
+			m.Series = &Series{}
+		}
+		s := m.Series
+		s.Name = info.Series
+		s.SortName = info.SeriesSort
+		s.Volume = info.Volume
+		s.Format = info.Format
+		s.IssueCount = info.Count
+		s.Lang = info.LanguageISO
+		if info.LocalizedSeries == "" {
+			if len(s.AlternativeNames) > 0 {
+				s.AlternativeNames = s.AlternativeNames[1:]
+			}
+		} else if len(s.AlternativeNames) == 0 {
+			s.AlternativeNames = []AlternativeName{{Value: info.LocalizedSeries}}
+		} else if s.AlternativeNames[0].Value != info.LocalizedSeries {
+			s.AlternativeNames[0] = AlternativeName{Value: info.LocalizedSeries}
+		}
+		if reflect.DeepEqual(*s, Series{}) {
+			m.Series = nil
+		}
+	}
+
+	if changed("Title") {
+		if len(m.Stories) == 0 && m.CollectionTitle != "" {
+			m.CollectionTitle = info.Title
+		} else {
+			var stories model.List
+			if info.Title != "" {
+				stories = strings.Split(info.Title, storySeparator)
+			}
+			m.Stories = updateResources(m.Stories, stories)
+		}
+	}
+
+	if changed("Year", "Month", "Day") {
+		m.CoverDate = ""
+		if info.Year > 0 {
+			month, day := maxInt64(info.Month, 1), maxInt64(info.Day, 1)
+			m.CoverDate = fmt.Sprintf("%04d-%02d-%02d", info.Year, month, day)
+		}
+	}
+
+	if changed("StoryArc", "StoryArcNumber") {
+		numbers := strings.Split(info.StoryArcNumber, ",")
+		var arcs []Arc
+		for i, name := range model.ParseList(info.StoryArc) {
+			arc := Arc{Name: name}
+			for _, a := range m.Arcs {
+				if strings.EqualFold(a.Name, name) {
+					arc = a
+				}
+			}
+			if i < len(numbers) && info.StoryArcNumber != "" {
+				arc.Number = strings.TrimSpace(numbers[i])
+			}
+			arcs = append(arcs, arc)
+		}
+		m.Arcs = arcs
+	}
+
+	if changed("GTIN") {
+		m.GTIN = nil
+		if digits := strings.NewReplacer("-", "", " ", "").Replace(info.GTIN); digits != "" {
+			if len(digits) == 10 || strings.HasPrefix(digits, "978") || strings.HasPrefix(digits, "979") {
+				m.GTIN = &GTIN{ISBN: info.GTIN}
+			} else {
+				m.GTIN = &GTIN{UPC: info.GTIN}
+			}
+		}
+	}
+
+	if changed("Web") {
+		web, _ := info.List("Web")
+		var urls []URL
+		for i, u := range web {
+			urls = append(urls, URL{Primary: i == 0 && len(web) > 1, Value: u})
+		}
+		m.URLs = urls
+	}
+
+	for _, cf := range creditFields {
+		if changed(cf.field) {
+			people, _ := info.List(cf.field)
+			m.updateCredits(cf.field, cf.role, people)
+		}
+	}
+
+	// Changes that don't read back as they were set have been lost
+	updated := m.ComicInfo()
+	// A partial date is padded to a full CoverDate, so an unset month or day reads back as 1
+	if info.Month == 0 && updated.Month == 1 {
+		updated.Month = 0
+	}
+	if info.Day == 0 && updated.Day == 1 {
+		updated.Day = 0
+	}
+	var unmapped []string
+	for _, f := range model.Fields() {
+		if changed(f.Name) && !reflect.DeepEqual(f.Get(updated), f.Get(info)) {
+			unmapped = append(unmapped, f.Name)
+		}
+	}
+	return unmapped
+}
+
+// updateCredits changes the credits for a ComicInfo field to the given people.
+// Roles of other fields, and roles with no ComicInfo equivalent, are kept.
+func (m *MetronInfo) updateCredits(field string, role string, people model.List) {
+	var credits []Credit
+	credited := map[string]bool{}
+	for _, c := range m.Credits {
+		var roles []Resource
+		for _, r := range c.Roles {
+			if roleFields[strings.ToLower(strings.TrimSpace(r.Value))] != field {
+				roles = append(roles, r)
+			} else if people.Contains(c.Creator.Value) {
+				roles = append(roles, r)
+				credited[strings.ToLower(c.Creator.Value)] = true
+			}
+		}
+		c.Roles = roles
+		credits = append(credits, c)
+	}
+
+	for _, person := range people {
+		if credited[strings.ToLower(person)] {
+			continue
+		}
+		added := false
+		for i := range credits {
+			if strings.EqualFold(credits[i].Creator.Value, person) {
+				credits[i].Roles = append(credits[i].Roles, Resource{Value: role})
+				added = true
+				break
+			}
+		}
+		if !added {
+			credits = append(credits, Credit{Creator: Resource{Value: person}, Roles: []Resource{{Value: role}}})
+		}
+	}
+
+	// Drop anyone left without a role
+	m.Credits = nil
+	for _, c := range credits {
+		if len(c.Roles) > 0 {
+			m.Credits = append(m.Credits, c)
+		}
+	}
+}
+
+// values returns the values of resources as a list.
+func values(resources []Resource) model.List {
+	var l model.List
+	for _, r := range resources {
+		l = l.Add(r.Value)
+	}
+	return l
+}
+
+// updateResources changes resources to match a list of values, keeping the IDs of values that are still present.
+func updateResources(resources []Resource, l model.List) []Resource {
+	var updated []Resource
+	for _, v := range l {
+		r := Resource{Value: v}
+		for _, existing := range resources {
+			if strings.EqualFold(existing.Value, v) {
+				r.ID = existing.ID
+				break
+			}
+		}
+		updated = append(updated, r)
+	}
+	return updated
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
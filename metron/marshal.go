@@ -0,0 +1,146 @@
+package metron
+
+import (
+	"encoding/xml"
+	"github.com/blissd/cbz/model"
+)
+
+// The XSD requires wrapper elements such as <Genres> to have at least one child, but encoding/xml
+// writes an empty wrapper for an empty slice. So MetronInfo is marshalled through metronInfoXml,
+// where each wrapper is a pointer that is nil, and so omitted, when there are no children.
+
+type metronInfoXml struct {
+	IDs             *idsXml                    `xml:"IDS"`
+	Publisher       *Publisher                 `xml:"Publisher"`
+	Series          *seriesXml                 `xml:"Series"`
+	CollectionTitle string                     `xml:"CollectionTitle,omitempty"`
+	Number          string                     `xml:"Number,omitempty"`
+	Stories         *storiesXml                `xml:"Stories"`
+	Summary         string                     `xml:"Summary,omitempty"`
+	Notes           string                     `xml:"Notes,omitempty"`
+	Prices          *pricesXml                 `xml:"Prices"`
+	CoverDate       string                     `xml:"CoverDate,omitempty"`
+	StoreDate       string                     `xml:"StoreDate,omitempty"`
+	PageCount       int64                      `xml:"PageCount,omitempty"`
+	Genres          *genresXml                 `xml:"Genres"`
+	Tags            *tagsXml                   `xml:"Tags"`
+	Arcs            *arcsXml                   `xml:"Arcs"`
+	Characters      *charactersXml             `xml:"Characters"`
+	Teams           *teamsXml                  `xml:"Teams"`
+	Universes       *universesXml              `xml:"Universes"`
+	Locations       *locationsXml              `xml:"Locations"`
+	Reprints        *reprintsXml               `xml:"Reprints"`
+	GTIN            *GTIN                      `xml:"GTIN"`
+	AgeRating       string                     `xml:"AgeRating,omitempty"`
+	URLs            *urlsXml                   `xml:"URLs"`
+	Credits         *creditsXml                `xml:"Credits"`
+	LastModified    string                     `xml:"LastModified,omitempty"`
+	Pages           model.ArrayOfComicPageInfo `xml:"Pages,omitempty"`
+	Unknown         []model.Element
+}
+
+type seriesXml struct {
+	ID               string               `xml:"id,attr,omitempty"`
+	Lang             string               `xml:"lang,attr,omitempty"`
+	Name             string               `xml:"Name"`
+	SortName         string               `xml:"SortName,omitempty"`
+	Volume           int64                `xml:"Volume,omitempty"`
+	Format           string               `xml:"Format,omitempty"`
+	StartYear        int64                `xml:"StartYear,omitempty"`
+	IssueCount       int64                `xml:"IssueCount,omitempty"`
+	VolumeCount      int64                `xml:"VolumeCount,omitempty"`
+	AlternativeNames *alternativeNamesXml `xml:"AlternativeNames"`
+}
+
+type idsXml struct{ ID []ID }
+type alternativeNamesXml struct{ AlternativeName []AlternativeName }
+type storiesXml struct{ Story []Resource }
+type pricesXml struct{ Price []Price }
+type genresXml struct{ Genre []Resource }
+type tagsXml struct{ Tag []Resource }
+type arcsXml struct{ Arc []Arc }
+type charactersXml struct{ Character []Resource }
+type teamsXml struct{ Team []Resource }
+type universesXml struct{ Universe []Universe }
+type locationsXml struct{ Location []Resource }
+type reprintsXml struct{ Reprint []Resource }
+type urlsXml struct{ URL []URL }
+type creditsXml struct{ Credit []Credit }
+
+func (m MetronInfo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	w := metronInfoXml{
+		Publisher:       m.Publisher,
+		CollectionTitle: m.CollectionTitle,
+		Number:          m.Number,
+		Summary:         m.Summary,
+		Notes:           m.Notes,
+		CoverDate:       m.CoverDate,
+		StoreDate:       m.StoreDate,
+		PageCount:       m.PageCount,
+		GTIN:            m.GTIN,
+		AgeRating:       m.AgeRating,
+		LastModified:    m.LastModified,
+		Unknown:         m.Unknown,
+	}
+
+	if s := m.Series; s != nil {
+		w.Series = &seriesXml{
+			ID:          s.ID,
+			Lang:        s.Lang,
+			Name:        s.Name,
+			SortName:    s.SortName,
+			Volume:      s.Volume,
+			Format:      s.Format,
+			StartYear:   s.StartYear,
+			IssueCount:  s.IssueCount,
+			VolumeCount: s.VolumeCount,
+		}
+		if len(s.AlternativeNames) > 0 {
+			w.Series.AlternativeNames = &alternativeNamesXml{s.AlternativeNames}
+		}
+	}
+
+	if len(m.IDs) > 0 {
+		w.IDs = &idsXml{m.IDs}
+	}
+	if len(m.Stories) > 0 {
+		w.Stories = &storiesXml{m.Stories}
+	}
+	if len(m.Prices) > 0 {
+		w.Prices = &pricesXml{m.Prices}
+	}
+	if len(m.Genres) > 0 {
+		w.Genres = &genresXml{m.Genres}
+	}
+	if len(m.Tags) > 0 {
+		w.Tags = &tagsXml{m.Tags}
+	}
+	if len(m.Arcs) > 0 {
+		w.Arcs = &arcsXml{m.Arcs}
+	}
+	if len(m.Characters) > 0 {
+		w.Characters = &charactersXml{m.Characters}
+	}
+	if len(m.Teams) > 0 {
+		w.Teams = &teamsXml{m.Teams}
+	}
+	if len(m.Universes) > 0 {
+		w.Universes = &universesXml{m.Universes}
+	}
+	if len(m.Locations) > 0 {
+		w.Locations = &locationsXml{m.Locations}
+	}
+	if len(m.Reprints) > 0 {
+		w.Reprints = &reprintsXml{m.Reprints}
+	}
+	if len(m.URLs) > 0 {
+		w.URLs = &urlsXml{m.URLs}
+	}
+	if len(m.Credits) > 0 {
+		w.Credits = &creditsXml{m.Credits}
+	}
+	w.Pages = m.Pages
+
+	start.Name = xml.Name{Local: "MetronInfo"}
+	return e.EncodeElement(w, start)
+}
@@ -0,0 +1,159 @@
+// Package metron models the MetronInfo.xml schema file, and maps it to and from ComicInfo.xml.
+// Schema is at version 1.0: https://github.com/Metron-Project/metroninfo/blob/master/schema/v1.0/MetronInfo.xsd
+package metron
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"github.com/blissd/cbz/model"
+	"io"
+	"path"
+	"strings"
+)
+
+const MetronInfoXmlName = "MetronInfo.xml"
+
+// IsMetronInfo reports whether a file name is a MetronInfo.xml file.
+// The file can be in a folder, and its name can be in any case.
+func IsMetronInfo(name string) bool {
+	return strings.EqualFold(path.Base(strings.ReplaceAll(name, "\\", "/")), MetronInfoXmlName)
+}
+
+// ID is an identifier of the issue in an external database, such as Metron or Comic Vine.
+type ID struct {
+	Source  string `xml:"source,attr"`
+	Primary bool   `xml:"primary,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+// Resource is a named entity with an optional database ID, such as a character or genre.
+type Resource struct {
+	ID    string `xml:"id,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type Imprint Resource
+
+type Publisher struct {
+	ID      string   `xml:"id,attr,omitempty"`
+	Name    string   `xml:"Name"`
+	Imprint *Imprint `xml:"Imprint,omitempty"`
+}
+
+type AlternativeName struct {
+	ID    string `xml:"id,attr,omitempty"`
+	Lang  string `xml:"lang,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type Series struct {
+	ID               string            `xml:"id,attr,omitempty"`
+	Lang             string            `xml:"lang,attr,omitempty"`
+	Name             string            `xml:"Name"`
+	SortName         string            `xml:"SortName,omitempty"`
+	Volume           int64             `xml:"Volume,omitempty"`
+	Format           string            `xml:"Format,omitempty"`
+	StartYear        int64             `xml:"StartYear,omitempty"`
+	IssueCount       int64             `xml:"IssueCount,omitempty"`
+	VolumeCount      int64             `xml:"VolumeCount,omitempty"`
+	AlternativeNames []AlternativeName `xml:"AlternativeNames>AlternativeName,omitempty"`
+}
+
+type Price struct {
+	Country string `xml:"country,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type Arc struct {
+	ID     string `xml:"id,attr,omitempty"`
+	Name   string `xml:"Name"`
+	Number string `xml:"Number,omitempty"`
+}
+
+type Universe struct {
+	ID          string `xml:"id,attr,omitempty"`
+	Name        string `xml:"Name"`
+	Designation string `xml:"Designation,omitempty"`
+}
+
+type GTIN struct {
+	ISBN string `xml:"ISBN,omitempty"`
+	UPC  string `xml:"UPC,omitempty"`
+}
+
+type URL struct {
+	Primary bool   `xml:"primary,attr,omitempty"`
+	Value   string `xml:",chardata"`
+}
+
+type Credit struct {
+	Creator Resource   `xml:"Creator"`
+	Roles   []Resource `xml:"Roles>Role"`
+}
+
+type MetronInfo struct {
+	XMLName         xml.Name                   `xml:"MetronInfo"`
+	IDs             []ID                       `xml:"IDS>ID,omitempty"`
+	Publisher       *Publisher                 `xml:"Publisher,omitempty"`
+	Series          *Series                    `xml:"Series,omitempty"`
+	CollectionTitle string                     `xml:"CollectionTitle,omitempty"`
+	Number          string                     `xml:"Number,omitempty"`
+	Stories         []Resource                 `xml:"Stories>Story,omitempty"`
+	Summary         string                     `xml:"Summary,omitempty"`
+	Notes           string                     `xml:"Notes,omitempty"`
+	Prices          []Price                    `xml:"Prices>Price,omitempty"`
+	CoverDate       string                     `xml:"CoverDate,omitempty"`
+	StoreDate       string                     `xml:"StoreDate,omitempty"`
+	PageCount       int64                      `xml:"PageCount,omitempty"`
+	Genres          []Resource                 `xml:"Genres>Genre,omitempty"`
+	Tags            []Resource                 `xml:"Tags>Tag,omitempty"`
+	Arcs            []Arc                      `xml:"Arcs>Arc,omitempty"`
+	Characters      []Resource                 `xml:"Characters>Character,omitempty"`
+	Teams           []Resource                 `xml:"Teams>Team,omitempty"`
+	Universes       []Universe                 `xml:"Universes>Universe,omitempty"`
+	Locations       []Resource                 `xml:"Locations>Location,omitempty"`
+	Reprints        []Resource                 `xml:"Reprints>Reprint,omitempty"`
+	GTIN            *GTIN                      `xml:"GTIN,omitempty"`
+	AgeRating       string                     `xml:"AgeRating,omitempty"`
+	URLs            []URL                      `xml:"URLs>URL,omitempty"`
+	Credits         []Credit                   `xml:"Credits>Credit,omitempty"`
+	LastModified    string                     `xml:"LastModified,omitempty"`
+	Pages           model.ArrayOfComicPageInfo `xml:"Pages,omitempty"`
+
+	// Unknown are elements not modelled by MetronInfo, in the order they were read.
+	Unknown []model.Element `xml:",any"`
+}
+
+// String returns the MetronInfo.xml document written by model.MarshalDocument, without the final new line.
+func (m *MetronInfo) String() string {
+	marshal, err := model.MarshalDocument(m)
+	if err != nil {
+		return "<invalid MetronInfo.xml>"
+	}
+	return strings.TrimSuffix(string(marshal), "\n")
+}
+
+func Unmarshal(file *zip.File) (*MetronInfo, error) {
+	if !IsMetronInfo(file.Name) {
+		return nil, fmt.Errorf("invalid file name: %v", file.Name)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip %s for reading: %w", file.Name, err)
+	}
+	defer r.Close()
+
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+	}
+
+	info := MetronInfo{}
+	err = xml.Unmarshal(bs, &info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to XML unmarshal %s: %w", file.Name, err)
+	}
+
+	return &info, nil
+}
@@ -0,0 +1,255 @@
+package metron
+
+import (
+	"encoding/xml"
+	"github.com/blissd/cbz/model"
+	"reflect"
+	"testing"
+)
+
+const example = `<?xml version="1.0" encoding="UTF-8"?>
+<MetronInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="MetronInfo.xsd">
+  <IDS>
+    <ID source="Metron" primary="true">290</ID>
+  </IDS>
+  <Publisher id="1">
+    <Name>Marvel</Name>
+  </Publisher>
+  <Series lang="en" id="2">
+    <Name>Death of the Inhumans</Name>
+    <SortName>Death of the Inhumans</SortName>
+    <Volume>1</Volume>
+    <Format>Limited Series</Format>
+  </Series>
+  <Number>1</Number>
+  <Stories>
+    <Story id="9">Chapter One: Vox</Story>
+  </Stories>
+  <Prices>
+    <Price country="US">4.99</Price>
+  </Prices>
+  <CoverDate>2018-09-01</CoverDate>
+  <Genres>
+    <Genre id="10">Super-Hero</Genre>
+  </Genres>
+  <Arcs>
+    <Arc id="3">
+      <Name>Death of the Inhumans</Name>
+      <Number>1</Number>
+    </Arc>
+  </Arcs>
+  <Characters>
+    <Character id="4">Black Bolt</Character>
+    <Character id="5">Medusa</Character>
+  </Characters>
+  <GTIN>
+    <UPC>75960608936900111</UPC>
+  </GTIN>
+  <AgeRating>Teen</AgeRating>
+  <URLs>
+    <URL primary="true">https://metron.cloud/issue/290</URL>
+  </URLs>
+  <Credits>
+    <Credit>
+      <Creator id="6">Al Ewing</Creator>
+      <Roles>
+        <Role id="7">Writer</Role>
+      </Roles>
+    </Credit>
+    <Credit>
+      <Creator id="8">Kim Jacinto</Creator>
+      <Roles>
+        <Role id="11">Artist</Role>
+        <Role id="12">Designer</Role>
+      </Roles>
+    </Credit>
+  </Credits>
+  <Extension>kept</Extension>
+</MetronInfo>`
+
+func TestMetronInfo_ComicInfo(t *testing.T) {
+	m := MetronInfo{}
+	if err := xml.Unmarshal([]byte(example), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	got := m.ComicInfo()
+	want := &model.ComicInfo{
+		Title:          "Chapter One: Vox",
+		Series:         "Death of the Inhumans",
+		SeriesSort:     "Death of the Inhumans",
+		Number:         "1",
+		Volume:         1,
+		Format:         "Limited Series",
+		LanguageISO:    "en",
+		Publisher:      "Marvel",
+		Year:           2018,
+		Month:          9,
+		Day:            1,
+		Genre:          "Super-Hero",
+		StoryArc:       "Death of the Inhumans",
+		StoryArcNumber: "1",
+		Characters:     "Black Bolt, Medusa",
+		GTIN:           "75960608936900111",
+		AgeRating:      "Teen",
+		Web:            "https://metron.cloud/issue/290",
+		Writer:         "Al Ewing",
+		Penciller:      "Kim Jacinto",
+		Version:        model.SchemaVersion21,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ComicInfo() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMetronInfo_Update(t *testing.T) {
+	m := MetronInfo{}
+	if err := xml.Unmarshal([]byte(example), &m); err != nil {
+		t.Fatal(err)
+	}
+	original := m
+
+	// Unchanged metadata leaves MetronInfo untouched
+	if unmapped := m.Update(m.ComicInfo()); unmapped != nil {
+		t.Errorf("Update() with unchanged ComicInfo unmapped = %v", unmapped)
+	}
+	if !reflect.DeepEqual(m, original) {
+		t.Errorf("Update() with unchanged ComicInfo changed MetronInfo: %+v", m)
+	}
+
+	info := m.ComicInfo()
+	info.Characters = "Black Bolt, Karnak"
+	info.Penciller = "Someone Else"
+	info.Month = 10
+	if unmapped := m.Update(info); unmapped != nil {
+		t.Errorf("Update() unmapped = %v, want none", unmapped)
+	}
+
+	wantCharacters := []Resource{{ID: "4", Value: "Black Bolt"}, {Value: "Karnak"}}
+	if !reflect.DeepEqual(m.Characters, wantCharacters) {
+		t.Errorf("Update() characters = %+v, want %+v", m.Characters, wantCharacters)
+	}
+
+	wantCredits := []Credit{
+		{Creator: Resource{ID: "6", Value: "Al Ewing"}, Roles: []Resource{{ID: "7", Value: "Writer"}}},
+		{Creator: Resource{ID: "8", Value: "Kim Jacinto"}, Roles: []Resource{{ID: "12", Value: "Designer"}}},
+		{Creator: Resource{Value: "Someone Else"}, Roles: []Resource{{Value: "Penciller"}}},
+	}
+	if !reflect.DeepEqual(m.Credits, wantCredits) {
+		t.Errorf("Update() credits = %+v, want %+v", m.Credits, wantCredits)
+	}
+
+	if m.CoverDate != "2018-10-01" {
+		t.Errorf("Update() CoverDate = %v", m.CoverDate)
+	}
+	if !reflect.DeepEqual(m.IDs, original.IDs) || !reflect.DeepEqual(m.Prices, original.Prices) || len(m.Unknown) != 1 {
+		t.Errorf("Update() lost unmapped elements: %+v", m)
+	}
+}
+
+func TestMetronInfo_Update_unmapped(t *testing.T) {
+	m := MetronInfo{}
+	if err := xml.Unmarshal([]byte(example), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	info := m.ComicInfo()
+	info.BlackAndWhite = "Yes"
+	info.Review = "Great"
+	info.AgeRating = "PG"
+	info.Summary = "New summary"
+
+	unmapped := m.Update(info)
+	want := []string{"BlackAndWhite", "AgeRating", "Review"}
+	if !reflect.DeepEqual(unmapped, want) {
+		t.Errorf("Update() unmapped = %v, want %v", unmapped, want)
+	}
+	if m.Summary != "New summary" {
+		t.Errorf("Update() Summary = %v", m.Summary)
+	}
+}
+
+func TestMetronInfo_Update_partialDate(t *testing.T) {
+	tests := []struct {
+		name string
+		date model.Date
+		want string
+	}{
+		{"Full", model.Date{Year: 2021, Month: 3, Day: 14}, "2021-03-14"},
+		{"Month", model.Date{Year: 2021, Month: 3}, "2021-03-01"},
+		{"Year", model.Date{Year: 2021}, "2021-01-01"},
+		{"None", model.Date{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := MetronInfo{}
+			if err := xml.Unmarshal([]byte(example), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			info := m.ComicInfo()
+			info.SetDate(tt.date)
+			if unmapped := m.Update(info); len(unmapped) > 0 {
+				t.Errorf("Update() unmapped = %v, want none", unmapped)
+			}
+			if m.CoverDate != tt.want {
+				t.Errorf("Update() CoverDate = %v, want %v", m.CoverDate, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMetronInfo(t *testing.T) {
+	for name, want := range map[string]bool{
+		"MetronInfo.xml":        true,
+		"metroninfo.XML":        true,
+		"folder/MetronInfo.xml": true,
+		`folder\MetronInfo.xml`: true,
+		"MetronInfo.xml.bak":    false,
+		"folder/ComicInfo.xml":  false,
+	} {
+		if got := IsMetronInfo(name); got != want {
+			t.Errorf("IsMetronInfo(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMetronInfo_String(t *testing.T) {
+	m, _ := FromComicInfo(&model.ComicInfo{Series: "Series", Writer: "Writer"})
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<MetronInfo>
+  <Series>
+    <Name>Series</Name>
+  </Series>
+  <Credits>
+    <Credit>
+      <Creator>Writer</Creator>
+      <Roles>
+        <Role>Writer</Role>
+      </Roles>
+    </Credit>
+  </Credits>
+</MetronInfo>`
+
+	if got := m.String(); got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
+
+func TestMetronInfo_pagesRoundTrip(t *testing.T) {
+	pages := model.ArrayOfComicPageInfo{{Image: 0, Type: "FrontCover"}, {Image: 1}}
+	m, _ := FromComicInfo(&model.ComicInfo{Series: "Series", Pages: pages})
+
+	bs, err := model.MarshalDocument(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := MetronInfo{}
+	if err = xml.Unmarshal(bs, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Pages, pages) {
+		t.Errorf("Pages = %+v, want %+v", got.Pages, pages)
+	}
+}
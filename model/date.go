@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date is a publication date made of the ComicInfo Year, Month and Day fields.
+// It can be partial: a year, a year and month, or a full date. Unknown parts are 0.
+type Date struct {
+	Year  int64
+	Month int64
+	Day   int64
+}
+
+// ParseDate parses an ISO 8601 date of the form "2021", "2021-03" or "2021-03-14".
+// A blank string is the zero Date.
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}, nil
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 || len(parts[0]) != 4 {
+		return Date{}, fmt.Errorf("invalid date %q: expected YYYY, YYYY-MM or YYYY-MM-DD", s)
+	}
+
+	var values [3]int64
+	for i, p := range parts {
+		if i > 0 && len(p) != 2 {
+			return Date{}, fmt.Errorf("invalid date %q: expected YYYY, YYYY-MM or YYYY-MM-DD", s)
+		}
+		v, err := strconv.ParseUint(p, 10, 16)
+		if err != nil {
+			return Date{}, fmt.Errorf("invalid date %q: %w", s, err)
+		}
+		values[i] = int64(v)
+	}
+
+	d := Date{Year: values[0], Month: values[1], Day: values[2]}
+	if err := d.validate(); err != nil {
+		return Date{}, fmt.Errorf("invalid date %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// DateOf returns the full Date of a time.
+func DateOf(t time.Time) Date {
+	return Date{Year: int64(t.Year()), Month: int64(t.Month()), Day: int64(t.Day())}
+}
+
+// IsZero reports whether no part of the date is known.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Time returns the start of the date in UTC, so a partial date is the first day of its year or month.
+// The zero Date is the zero time.Time.
+func (d Date) Time() time.Time {
+	if d.IsZero() {
+		return time.Time{}
+	}
+	month, day := d.Month, d.Day
+	if month == 0 {
+		month = 1
+	}
+	if day == 0 {
+		day = 1
+	}
+	return time.Date(int(d.Year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC)
+}
+
+// String formats the date as ISO 8601, with only its known parts.
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.Month == 0:
+		return fmt.Sprintf("%04d", d.Year)
+	case d.Day == 0:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// validate checks that the parts of a date are in range, filled from the year down, and that the day exists.
+func (d Date) validate() error {
+	switch {
+	case d.IsZero():
+		return nil
+	case d.Year < 1 || d.Year > 9999:
+		return fmt.Errorf("year %d is not between 1 and 9999", d.Year)
+	case d.Month < 0 || d.Month > 12:
+		return fmt.Errorf("month %d is not between 1 and 12", d.Month)
+	case d.Day > 0 && d.Month == 0:
+		return fmt.Errorf("day requires a month")
+	case d.Day < 0 || (d.Day > 0 && d.Day > daysIn(d.Year, d.Month)):
+		return fmt.Errorf("%s %d has no day %d", time.Month(d.Month), d.Year, d.Day)
+	}
+	return nil
+}
+
+// daysIn returns the number of days in a month.
+func daysIn(year, month int64) int64 {
+	return int64(time.Date(int(year), time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day())
+}
+
+// Date returns the publication date. Year, Month and Day values of -1, meaning unknown, are 0.
+func (c *ComicInfo) Date() Date {
+	d := Date{Year: c.Year, Month: c.Month, Day: c.Day}
+	if d.Year < 0 {
+		d.Year = 0
+	}
+	if d.Month < 0 {
+		d.Month = 0
+	}
+	if d.Day < 0 {
+		d.Day = 0
+	}
+	return d
+}
+
+// SetDate sets the Year, Month and Day fields. Unknown parts of the date clear their field.
+func (c *ComicInfo) SetDate(d Date) {
+	c.Year, c.Month, c.Day = d.Year, d.Month, d.Day
+}
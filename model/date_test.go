@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Date
+		wantErr bool
+	}{
+		{"", Date{}, false},
+		{"2021", Date{Year: 2021}, false},
+		{"2021-03", Date{Year: 2021, Month: 3}, false},
+		{"2021-03-14", Date{Year: 2021, Month: 3, Day: 14}, false},
+		{"2020-02-29", Date{Year: 2020, Month: 2, Day: 29}, false},
+		{"2021-02-29", Date{}, true},
+		{"2021-04-31", Date{}, true},
+		{"2021-13", Date{}, true},
+		{"2021-00-01", Date{}, true},
+		{"21-03", Date{}, true},
+		{"2021-3", Date{}, true},
+		{"2021-03-14T00:00:00Z", Date{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseDate(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDate() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.s {
+				t.Errorf("String() = %v, want %v", got.String(), tt.s)
+			}
+		})
+	}
+}
+
+func TestDate_Time(t *testing.T) {
+	if got, want := (Date{Year: 2021, Month: 3}).Time(), time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Time() = %v, want %v", got, want)
+	}
+	if got := (Date{}).Time(); !got.IsZero() {
+		t.Errorf("Time() of zero Date = %v", got)
+	}
+	if got, want := DateOf(time.Date(2021, 3, 14, 15, 9, 26, 0, time.UTC)), (Date{2021, 3, 14}); got != want {
+		t.Errorf("DateOf() = %v, want %v", got, want)
+	}
+}
+
+func TestComicInfo_setDateField(t *testing.T) {
+	info := ComicInfo{Year: 1999, Month: 12, Day: 31}
+
+	f, err := LookupField("date")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := f.Parse("2021-03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Set(&info, v); err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Year != 2021 || info.Month != 3 || info.Day != 0 {
+		t.Errorf("Set() = %v-%v-%v, want 2021-3-0", info.Year, info.Month, info.Day)
+	}
+	if got := f.Get(&info); got != (Date{Year: 2021, Month: 3}) {
+		t.Errorf("Get() = %v", got)
+	}
+	for _, f := range Fields() {
+		if f.Name == "Date" {
+			t.Errorf("Fields() should not list the virtual Date field")
+		}
+	}
+}
+
+func TestComicInfo_Validate_impossibleDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    ComicInfo
+		wantErr bool
+	}{
+		{"Leap day", ComicInfo{Year: 2020, Month: 2, Day: 29}, false},
+		{"Not a leap year", ComicInfo{Year: 2021, Month: 2, Day: 29}, true},
+		{"April 31", ComicInfo{Year: 2021, Month: 4, Day: 31}, true},
+		{"Unknown year", ComicInfo{Year: -1, Month: 2, Day: 29}, true}, // Month requires Year
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.info.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var ve *ValidationError
+			if err != nil && (!errors.As(err, &ve) || len(ve.Errors) != 1) {
+				t.Errorf("Validate() = %v, want a single field error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"strings"
+)
+
+// Format is a text encoding of ComicInfo metadata.
+// JSON and YAML documents use the XML element and attribute names as keys.
+// Unknown elements are kept as a list of names, attributes and inner XML, and attributes that aren't modelled
+// as lists of names and values, so a document decoded from JSON or YAML is written as the same XML.
+type Format string
+
+const (
+	FormatXML  Format = "xml"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat parses a format name: xml, json, yaml or yml.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "xml":
+		return FormatXML, nil
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unknown format: %v", s)
+}
+
+// DetectFormat guesses the format of a document from its first non-blank character.
+func DetectFormat(bs []byte) Format {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(bs, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatXML
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// Encode writes a ComicInfo in the given format.
+func Encode(w io.Writer, info *ComicInfo, format Format) error {
+	var bs []byte
+	var err error
+
+	switch format {
+	case FormatXML:
+		bs, err = Marshal(info)
+		bs = bytes.TrimSuffix(bs, []byte("\n"))
+	case FormatJSON:
+		// Unknown elements hold XML, which is more readable without escaping
+		b := bytes.Buffer{}
+		enc := json.NewEncoder(&b)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		err = enc.Encode(info)
+		bs = bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	case FormatYAML:
+		b := bytes.Buffer{}
+		enc := yaml.NewEncoder(&b)
+		enc.SetIndent(2)
+		err = enc.Encode(info)
+		bs = bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+	default:
+		err = fmt.Errorf("unknown format: %v", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to encode ComicInfo as %s: %w", format, err)
+	}
+
+	if _, err = w.Write(append(bs, '\n')); err != nil {
+		return fmt.Errorf("failed to write ComicInfo: %w", err)
+	}
+	return nil
+}
+
+// Decode reads a ComicInfo in the given format.
+// Unknown JSON and YAML keys are an error, as they are most likely misspelt field names.
+func Decode(r io.Reader, format Format) (*ComicInfo, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ComicInfo: %w", err)
+	}
+
+	info := ComicInfo{}
+
+	switch format {
+	case FormatXML:
+		err = xml.Unmarshal(bs, &info)
+	case FormatJSON:
+		err = decodeJSON(bs, &info)
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(bs))
+		dec.KnownFields(true)
+		err = dec.Decode(&info)
+		if err == io.EOF {
+			err = nil // empty document
+		}
+	default:
+		err = fmt.Errorf("unknown format: %v", format)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode ComicInfo as %s: %w", format, err)
+	}
+
+	info.Version = info.RequiredVersion()
+	return &info, nil
+}
+
+// decodeJSON decodes a JSON document. Unknown keys are an error.
+func decodeJSON(bs []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// encodedAttr is an XML attribute in a JSON or YAML document.
+type encodedAttr struct {
+	Name  string `json:"Name" yaml:"Name"`
+	Value string `json:"Value" yaml:"Value"`
+}
+
+func (a Attrs) encode() []encodedAttr {
+	encoded := make([]encodedAttr, len(a))
+	for i, attr := range a {
+		encoded[i] = encodedAttr{Name: name(attr.Name), Value: attr.Value}
+	}
+	return encoded
+}
+
+func (a *Attrs) decode(encoded []encodedAttr) {
+	*a = nil
+	for _, attr := range encoded {
+		*a = append(*a, xml.Attr{Name: xml.Name{Local: attr.Name}, Value: attr.Value})
+	}
+}
+
+func (a Attrs) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.encode())
+}
+
+func (a *Attrs) UnmarshalJSON(bs []byte) error {
+	var encoded []encodedAttr
+	if err := decodeJSON(bs, &encoded); err != nil {
+		return err
+	}
+	a.decode(encoded)
+	return nil
+}
+
+func (a Attrs) MarshalYAML() (any, error) {
+	return a.encode(), nil
+}
+
+func (a *Attrs) UnmarshalYAML(n *yaml.Node) error {
+	var encoded []encodedAttr
+	if err := n.Decode(&encoded); err != nil {
+		return err
+	}
+	a.decode(encoded)
+	return nil
+}
+
+// encodedElement is an unknown element in a JSON or YAML document, with its inner XML as text.
+type encodedElement struct {
+	Name    string `json:"Name" yaml:"Name"`
+	Attrs   Attrs  `json:"Attrs,omitempty" yaml:"Attrs,omitempty"`
+	Content string `json:"Content,omitempty" yaml:"Content,omitempty"`
+	Before  string `json:"Before,omitempty" yaml:"Before,omitempty"`
+}
+
+func (e Element) encode() encodedElement {
+	return encodedElement{Name: name(e.XMLName), Attrs: e.Attrs, Content: e.Content, Before: e.Before}
+}
+
+func (e *Element) decode(encoded encodedElement) error {
+	if encoded.Name == "" {
+		return fmt.Errorf("unknown element has no name")
+	}
+	*e = Element{XMLName: xml.Name{Local: encoded.Name}, Attrs: encoded.Attrs, Content: encoded.Content, Before: encoded.Before}
+	return nil
+}
+
+func (e Element) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.encode())
+}
+
+func (e *Element) UnmarshalJSON(bs []byte) error {
+	var encoded encodedElement
+	if err := decodeJSON(bs, &encoded); err != nil {
+		return err
+	}
+	return e.decode(encoded)
+}
+
+func (e Element) MarshalYAML() (any, error) {
+	return e.encode(), nil
+}
+
+func (e *Element) UnmarshalYAML(n *yaml.Node) error {
+	var encoded encodedElement
+	if err := n.Decode(&encoded); err != nil {
+		return err
+	}
+	return e.decode(encoded)
+}
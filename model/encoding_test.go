@@ -0,0 +1,139 @@
+package model
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncode_roundTrip(t *testing.T) {
+	info := &ComicInfo{
+		Title:     "Watchmen",
+		Number:    "1",
+		Year:      1986,
+		Writer:    "Alan Moore",
+		Tags:      "Classic",
+		PageCount: 2,
+		Pages: []ComicPageInfo{
+			{Image: 0, Type: "FrontCover", ImageWidth: 1000},
+			{Image: 1, DoublePage: true},
+		},
+		Version: SchemaVersion21,
+	}
+
+	for _, format := range []Format{FormatXML, FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			b := bytes.Buffer{}
+			if err := Encode(&b, info, format); err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectFormat(b.Bytes()); got != format {
+				t.Errorf("DetectFormat() = %v, want %v", got, format)
+			}
+			got, err := Decode(&b, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, info) {
+				t.Errorf("Decode() = %v, want %v", got, info)
+			}
+		})
+	}
+}
+
+func TestEncode_keepsUnknown(t *testing.T) {
+	info := &ComicInfo{
+		Title:        "Watchmen",
+		Number:       "1",
+		PageCount:    1,
+		Pages:        []ComicPageInfo{{Image: 0, Attrs: Attrs{{Name: xml.Name{Local: "Custom"}, Value: "yes"}}}},
+		Attrs:        Attrs{{Name: xml.Name{Local: "xmlns:foo"}, Value: "urn:foo"}},
+		ElementAttrs: map[string]Attrs{"Title": {{Name: xml.Name{Local: "lang"}, Value: "en"}}},
+		Unknown: []Element{
+			{XMLName: xml.Name{Local: "foo:Ext"}, Attrs: Attrs{{Name: xml.Name{Local: "foo:a"}, Value: "1"}}, Content: "a<b>x</b>c", Before: "Number"},
+			{XMLName: xml.Name{Local: "Last"}},
+		},
+		Version: SchemaVersion20,
+	}
+	want, err := Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []Format{FormatXML, FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			b := bytes.Buffer{}
+			if err := Encode(&b, info, format); err != nil {
+				t.Fatal(err)
+			}
+			if format != FormatXML && !strings.Contains(b.String(), "foo:Ext") {
+				t.Errorf("Encode() dropped unknown elements: %v", b.String())
+			}
+			decoded, err := Decode(&b, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, info) {
+				t.Errorf("Decode() = %+v, want %+v", decoded, info)
+			}
+			got, err := Marshal(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Marshal() want: %v, got: %v", string(want), string(got))
+			}
+		})
+	}
+}
+
+func TestEncode_fieldNames(t *testing.T) {
+	info := &ComicInfo{AlternativeSeries: "Minutemen", Pages: []ComicPageInfo{{Image: 0, Type: "FrontCover"}}}
+
+	b := bytes.Buffer{}
+	if err := Encode(&b, info, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "AlternateSeries": "Minutemen",
+  "Pages": [
+    {
+      "Image": 0,
+      "Type": "FrontCover"
+    }
+  ]
+}
+`
+	if b.String() != want {
+		t.Errorf("Encode() = %v, want %v", b.String(), want)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		format  Format
+		want    *ComicInfo
+		wantErr bool
+	}{
+		{"JSON", `{"Series": "Saga", "Volume": 1}`, FormatJSON, &ComicInfo{Series: "Saga", Volume: 1, Version: SchemaVersion20}, false},
+		{"YAML", "Series: Saga\nGTIN: \"9781607066019\"\n", FormatYAML, &ComicInfo{Series: "Saga", GTIN: "9781607066019", Version: SchemaVersion21}, false},
+		{"Empty YAML", "", FormatYAML, &ComicInfo{Version: SchemaVersion20}, false},
+		{"Unknown JSON key", `{"Sereis": "Saga"}`, FormatJSON, nil, true},
+		{"Unknown YAML key", "Sereis: Saga\n", FormatYAML, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(strings.NewReader(tt.doc), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the data type of a ComicInfo field.
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindEnum
+	KindDate
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "int"
+	case KindFloat:
+		return "float"
+	case KindEnum:
+		return "enum"
+	case KindDate:
+		return "date"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Field describes a single valued ComicInfo.xml element, and how to get and set it on a ComicInfo.
+// Pages is not a single valued element, so has no Field.
+type Field struct {
+	// Name is the XML element name.
+	Name string
+
+	// Kind is the data type of the field.
+	Kind Kind
+
+	// Values are the allowed values of a KindEnum field. A blank value is always allowed.
+	Values []string
+
+	// Version is the schema version that introduced the field.
+	Version SchemaVersion
+
+	// Multi reports whether a KindString field holds a comma separated list of values.
+	Multi bool
+
+	get func(c *ComicInfo) any
+	set func(c *ComicInfo, v any) bool
+}
+
+// Get returns the value of the field: a string for KindString and KindEnum, an int64 for KindInt,
+// a float64 for KindFloat and a Date for KindDate.
+func (f *Field) Get(c *ComicInfo) any {
+	return f.get(c)
+}
+
+// Set sets the value of the field. The value must have the type returned by Get.
+func (f *Field) Set(c *ComicInfo, v any) error {
+	if !f.set(c, v) {
+		return fmt.Errorf("field %s has %s type, not %T", f.Name, f.Kind, v)
+	}
+	return nil
+}
+
+// IsZero reports whether the field is unset.
+func (f *Field) IsZero(c *ComicInfo) bool {
+	switch v := f.get(c).(type) {
+	case string:
+		return v == ""
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	case Date:
+		return v.IsZero()
+	}
+	return false
+}
+
+// Parse converts the string representation of a value to the data type of the field.
+func (f *Field) Parse(s string) (any, error) {
+	switch f.Kind {
+	case KindInt:
+		return strconv.ParseInt(s, 10, 64)
+	case KindFloat:
+		return strconv.ParseFloat(s, 64)
+	case KindDate:
+		return ParseDate(s)
+	}
+	return s, nil
+}
+
+func stringField(name string, version SchemaVersion, ptr func(c *ComicInfo) *string) *Field {
+	f := enumField(name, nil, ptr)
+	f.Kind = KindString
+	f.Version = version
+	return f
+}
+
+func listField(name string, version SchemaVersion, ptr func(c *ComicInfo) *string) *Field {
+	f := stringField(name, version, ptr)
+	f.Multi = true
+	return f
+}
+
+func enumField(name string, values []string, ptr func(c *ComicInfo) *string) *Field {
+	return &Field{
+		Name:    name,
+		Kind:    KindEnum,
+		Values:  values,
+		Version: SchemaVersion20,
+		get:     func(c *ComicInfo) any { return *ptr(c) },
+		set: func(c *ComicInfo, v any) bool {
+			s, ok := v.(string)
+			if ok {
+				*ptr(c) = s
+			}
+			return ok
+		},
+	}
+}
+
+func intField(name string, ptr func(c *ComicInfo) *int64) *Field {
+	return &Field{
+		Name:    name,
+		Kind:    KindInt,
+		Version: SchemaVersion20,
+		get:     func(c *ComicInfo) any { return *ptr(c) },
+		set: func(c *ComicInfo, v any) bool {
+			i, ok := v.(int64)
+			if ok {
+				*ptr(c) = i
+			}
+			return ok
+		},
+	}
+}
+
+func floatField(name string, ptr func(c *ComicInfo) *float64) *Field {
+	return &Field{
+		Name:    name,
+		Kind:    KindFloat,
+		Version: SchemaVersion20,
+		get:     func(c *ComicInfo) any { return *ptr(c) },
+		set: func(c *ComicInfo, v any) bool {
+			f, ok := v.(float64)
+			if ok {
+				*ptr(c) = f
+			}
+			return ok
+		},
+	}
+}
+
+// fields lists every single valued ComicInfo.xml element in schema order.
+var fields = []*Field{
+	stringField("Title", SchemaVersion20, func(c *ComicInfo) *string { return &c.Title }),
+	stringField("Series", SchemaVersion20, func(c *ComicInfo) *string { return &c.Series }),
+	stringField("Number", SchemaVersion20, func(c *ComicInfo) *string { return &c.Number }),
+	intField("Count", func(c *ComicInfo) *int64 { return &c.Count }),
+	intField("Volume", func(c *ComicInfo) *int64 { return &c.Volume }),
+	stringField("AlternateSeries", SchemaVersion20, func(c *ComicInfo) *string { return &c.AlternativeSeries }),
+	stringField("AlternateNumber", SchemaVersion20, func(c *ComicInfo) *string { return &c.AlternativeNumber }),
+	intField("AlternateCount", func(c *ComicInfo) *int64 { return &c.AlternativeCount }),
+	stringField("Summary", SchemaVersion20, func(c *ComicInfo) *string { return &c.Summary }),
+	stringField("Notes", SchemaVersion20, func(c *ComicInfo) *string { return &c.Notes }),
+	intField("Year", func(c *ComicInfo) *int64 { return &c.Year }),
+	intField("Month", func(c *ComicInfo) *int64 { return &c.Month }),
+	intField("Day", func(c *ComicInfo) *int64 { return &c.Day }),
+	listField("Writer", SchemaVersion20, func(c *ComicInfo) *string { return &c.Writer }),
+	listField("Penciller", SchemaVersion20, func(c *ComicInfo) *string { return &c.Penciller }),
+	listField("Inker", SchemaVersion20, func(c *ComicInfo) *string { return &c.Inker }),
+	listField("Colorist", SchemaVersion20, func(c *ComicInfo) *string { return &c.Colorist }),
+	listField("Letterer", SchemaVersion20, func(c *ComicInfo) *string { return &c.Letterer }),
+	listField("CoverArtist", SchemaVersion20, func(c *ComicInfo) *string { return &c.CoverArtist }),
+	listField("Editor", SchemaVersion20, func(c *ComicInfo) *string { return &c.Editor }),
+	listField("Translator", SchemaVersion21, func(c *ComicInfo) *string { return &c.Translator }),
+	stringField("Publisher", SchemaVersion20, func(c *ComicInfo) *string { return &c.Publisher }),
+	stringField("Imprint", SchemaVersion20, func(c *ComicInfo) *string { return &c.Imprint }),
+	listField("Genre", SchemaVersion20, func(c *ComicInfo) *string { return &c.Genre }),
+	listField("Tags", SchemaVersion21, func(c *ComicInfo) *string { return &c.Tags }),
+	listField("Web", SchemaVersion20, func(c *ComicInfo) *string { return &c.Web }),
+	intField("PageCount", func(c *ComicInfo) *int64 { return &c.PageCount }),
+	stringField("LanguageISO", SchemaVersion20, func(c *ComicInfo) *string { return &c.LanguageISO }),
+	stringField("Format", SchemaVersion20, func(c *ComicInfo) *string { return &c.Format }),
+	enumField("BlackAndWhite", yesNoValues, func(c *ComicInfo) *string { return (*string)(&c.BlackAndWhite) }),
+	enumField("Manga", mangaValues, func(c *ComicInfo) *string { return (*string)(&c.Manga) }),
+	listField("Characters", SchemaVersion20, func(c *ComicInfo) *string { return &c.Characters }),
+	listField("Teams", SchemaVersion20, func(c *ComicInfo) *string { return &c.Teams }),
+	listField("Locations", SchemaVersion20, func(c *ComicInfo) *string { return &c.Locations }),
+	stringField("ScanInformation", SchemaVersion20, func(c *ComicInfo) *string { return &c.ScanInformation }),
+	listField("StoryArc", SchemaVersion20, func(c *ComicInfo) *string { return &c.StoryArc }),
+	stringField("StoryArcNumber", SchemaVersion21, func(c *ComicInfo) *string { return &c.StoryArcNumber }),
+	stringField("SeriesGroup", SchemaVersion20, func(c *ComicInfo) *string { return &c.SeriesGroup }),
+	enumField("AgeRating", ageRatingValues, func(c *ComicInfo) *string { return (*string)(&c.AgeRating) }),
+	floatField("CommunityRating", func(c *ComicInfo) *float64 { return (*float64)(&c.CommunityRating) }),
+	stringField("MainCharacterOrTeam", SchemaVersion20, func(c *ComicInfo) *string { return &c.MainCharacterOrTeam }),
+	stringField("Review", SchemaVersion20, func(c *ComicInfo) *string { return &c.Review }),
+	stringField("GTIN", SchemaVersion21, func(c *ComicInfo) *string { return &c.GTIN }),
+	stringField("LocalizedSeries", SchemaVersion21, func(c *ComicInfo) *string { return &c.LocalizedSeries }),
+	stringField("SeriesSort", SchemaVersion21, func(c *ComicInfo) *string { return &c.SeriesSort }),
+	stringField("TitleSort", SchemaVersion21, func(c *ComicInfo) *string { return &c.TitleSort }),
+}
+
+// virtualFields are computed from other fields. They can be looked up and set, but are not listed by Fields.
+var virtualFields = []*Field{
+	{
+		Name:    "Date",
+		Kind:    KindDate,
+		Version: SchemaVersion20,
+		get:     func(c *ComicInfo) any { return c.Date() },
+		set: func(c *ComicInfo, v any) bool {
+			d, ok := v.(Date)
+			if ok {
+				c.SetDate(d)
+			}
+			return ok
+		},
+	},
+}
+
+// lookupFields returns the fields and virtual fields that can be looked up by name.
+func lookupFields() []*Field {
+	return append(Fields(), virtualFields...)
+}
+
+// fieldAliases maps the Go names of ComicInfo fields to XML element names, where they differ.
+var fieldAliases = map[string]string{
+	"AlternativeSeries": "AlternateSeries",
+	"AlternativeNumber": "AlternateNumber",
+	"AlternativeCount":  "AlternateCount",
+}
+
+// Fields returns every single valued ComicInfo.xml field in schema order.
+func Fields() []*Field {
+	return append([]*Field(nil), fields...)
+}
+
+// UnknownFieldError is returned when looking up a field name that doesn't exist.
+type UnknownFieldError struct {
+	Name string
+
+	// Suggestions are similarly spelt field names, closest first.
+	Suggestions []string
+}
+
+func (e *UnknownFieldError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown field %q", e.Name)
+	}
+	return fmt.Sprintf("unknown field %q, did you mean %q?", e.Name, strings.Join(e.Suggestions, `" or "`))
+}
+
+// LookupField finds a field by name, ignoring case.
+// An unknown name returns an *UnknownFieldError with suggestions for what might have been meant.
+func LookupField(name string) (*Field, error) {
+	for alias, n := range fieldAliases {
+		if strings.EqualFold(alias, name) {
+			name = n
+		}
+	}
+	for _, f := range lookupFields() {
+		if strings.EqualFold(f.Name, name) {
+			return f, nil
+		}
+	}
+	return nil, &UnknownFieldError{Name: name, Suggestions: suggestFields(name)}
+}
+
+// suggestFields finds field names within a small edit distance of name.
+func suggestFields(name string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	lower := strings.ToLower(name)
+	maxDistance := len(lower)/3 + 1
+
+	var suggestions []suggestion
+	for _, f := range lookupFields() {
+		d := levenshtein(lower, strings.ToLower(f.Name))
+		if d <= maxDistance {
+			suggestions = append(suggestions, suggestion{f.Name, d})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	var names []string
+	for i, s := range suggestions {
+		if i == 3 {
+			break
+		}
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// levenshtein computes the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// Convert a string representation of a value to the correct data type for the named field.
+func Convert(name string, value string) (any, error) {
+	f, err := LookupField(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.Parse(value)
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLookupField(t *testing.T) {
+	tests := []struct {
+		name            string
+		lookup          string
+		want            string
+		wantSuggestions []string
+	}{
+		{"Exact", "AgeRating", "AgeRating", nil},
+		{"Case-insensitive", "agerating", "AgeRating", nil},
+		{"Alias", "AlternativeSeries", "AlternateSeries", nil},
+		{"Misspelt", "Charcters", "", []string{"Characters"}},
+		{"Nothing close", "Unrelated", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LookupField(tt.lookup)
+			if tt.want != "" {
+				if err != nil {
+					t.Fatalf("LookupField() error = %v", err)
+				}
+				if got.Name != tt.want {
+					t.Errorf("LookupField() got = %v, want %v", got.Name, tt.want)
+				}
+				return
+			}
+
+			var uerr *UnknownFieldError
+			if !errors.As(err, &uerr) {
+				t.Fatalf("LookupField() error = %v, want *UnknownFieldError", err)
+			}
+			if !reflect.DeepEqual(uerr.Suggestions, tt.wantSuggestions) {
+				t.Errorf("LookupField() suggestions = %v, want %v", uerr.Suggestions, tt.wantSuggestions)
+			}
+		})
+	}
+}
+
+func TestField_GetSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		value   any
+		want    ComicInfo
+		wantErr bool
+	}{
+		{"String", "Title", "Title", ComicInfo{Title: "Title"}, false},
+		{"Enum", "Manga", "Yes", ComicInfo{Manga: "Yes"}, false},
+		{"Int", "Volume", int64(2), ComicInfo{Volume: 2}, false},
+		{"Float", "CommunityRating", 4.5, ComicInfo{CommunityRating: 4.5}, false},
+		{"Wrong type", "Volume", "2", ComicInfo{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := LookupField(tt.field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info := ComicInfo{}
+			if err = f.Set(&info, tt.value); (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(info, tt.want) {
+				t.Errorf("Set() = %+v, want %+v", info, tt.want)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(f.Get(&info), tt.value) {
+				t.Errorf("Get() = %v, want %v", f.Get(&info), tt.value)
+			}
+		})
+	}
+}
+
+func TestFields_coverComicInfo(t *testing.T) {
+	// Every exported ComicInfo field other than these should be in the registry.
+	skip := map[string]bool{"Pages": true, "Unknown": true, "Attrs": true, "ElementAttrs": true, "Version": true}
+
+	registered := map[string]bool{}
+	info := ComicInfo{}
+	rv := reflect.ValueOf(&info).Elem()
+	for _, f := range Fields() {
+		// Set each field to a non-zero value so it can be matched against the struct field
+		switch f.Kind {
+		case KindString, KindEnum:
+			_ = f.Set(&info, "x")
+		case KindInt:
+			_ = f.Set(&info, int64(1))
+		case KindFloat:
+			_ = f.Set(&info, 1.0)
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			if !rv.Field(i).IsZero() {
+				registered[rv.Type().Field(i).Name] = true
+			}
+		}
+		info = ComicInfo{}
+	}
+
+	for i := 0; i < rv.NumField(); i++ {
+		name := rv.Type().Field(i).Name
+		if !skip[name] && !registered[name] {
+			t.Errorf("ComicInfo.%s is not in the field registry", name)
+		}
+	}
+}
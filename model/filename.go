@@ -0,0 +1,101 @@
+package model
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// fileNameGroup is a parenthesised or bracketed part of a file name, such as "(2019)" or "[Digital]".
+	fileNameGroup = regexp.MustCompile(`\(([^)]*)\)|\[([^]]*)]`)
+
+	// fileNameYear is a year, optionally with a month, such as "2019" or "2019-05".
+	fileNameYear = regexp.MustCompile(`^((?:19|20)\d\d)(?:-(\d\d))?$`)
+
+	// fileNameVolume is a volume, such as "v2", "v02", "Vol. 2" or "Volume 2".
+	fileNameVolume = regexp.MustCompile(`(?i)(?:^|\s)v(?:ol(?:ume)?)?\.?\s?(\d+)(?:\s|$)`)
+
+	// fileNameNumber is an issue number after a hash, such as "#12" or "#1.5".
+	fileNameNumber = regexp.MustCompile(`(?:^|\s)#\s?(\S+)`)
+
+	// fileNameTrailingNumber is an issue number at the end of a name, such as "Saga 055".
+	fileNameTrailingNumber = regexp.MustCompile(`\s(-?\d+(?:\.\d+)?[a-zA-Z]?)$`)
+)
+
+// ParseFileName infers Series, Volume, Number, Title, Year and Month from the name of a comic file,
+// such as "Series v02 #012 - Title (2019).cbz", the form used by "cbz rename", or "Series_012_(2019)_(Digital).cbr".
+// Fields that can't be inferred are left empty.
+func ParseFileName(name string) *ComicInfo {
+	info := &ComicInfo{}
+
+	s := filepath.Base(filepath.ToSlash(name))
+	if ext := filepath.Ext(s); isFileExtension(ext) {
+		s = strings.TrimSuffix(s, ext)
+	}
+	if !strings.Contains(s, " ") {
+		s = strings.ReplaceAll(s, "_", " ")
+	}
+
+	// Parenthesised groups hold the year, and tags such as "(Digital)" that are dropped
+	for _, group := range fileNameGroup.FindAllStringSubmatch(s, -1) {
+		m := fileNameYear.FindStringSubmatch(strings.TrimSpace(group[1] + group[2]))
+		if m != nil && info.Year == 0 {
+			info.Year, _ = strconv.ParseInt(m[1], 10, 64)
+			if m[2] != "" {
+				info.Month, _ = strconv.ParseInt(m[2], 10, 64)
+			}
+		}
+	}
+	s = strings.Join(strings.Fields(fileNameGroup.ReplaceAllString(s, " ")), " ")
+
+	// A title follows the volume or number, as in "Series #1 - Title"
+	head, title, found := strings.Cut(s, " - ")
+	if found && (fileNameVolume.MatchString(head) || fileNameNumber.MatchString(head) || fileNameTrailingNumber.MatchString(head)) {
+		s = head
+		info.Title = strings.TrimSpace(title)
+	}
+
+	if loc := fileNameVolume.FindStringSubmatchIndex(s); loc != nil {
+		info.Volume, _ = strconv.ParseInt(s[loc[2]:loc[3]], 10, 64)
+		s = s[:loc[0]] + " " + s[loc[1]:]
+	}
+
+	if loc := fileNameNumber.FindStringSubmatchIndex(s); loc != nil {
+		info.Number = trimIssueNumber(s[loc[2]:loc[3]])
+		s = s[:loc[0]] + " " + s[loc[1]:]
+	} else if loc := fileNameTrailingNumber.FindStringSubmatchIndex(strings.TrimSpace(s)); loc != nil {
+		s = strings.TrimSpace(s)
+		info.Number = trimIssueNumber(s[loc[2]:loc[3]])
+		s = s[:loc[0]]
+	}
+
+	info.Series = strings.Trim(strings.Join(strings.Fields(s), " "), " -")
+	return info
+}
+
+// isFileExtension reports whether ext is a file extension, rather than part of a name such as "v1.5".
+func isFileExtension(ext string) bool {
+	if len(ext) < 2 || len(ext) > 5 {
+		return false
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return strings.ContainsAny(strings.ToLower(ext), "abcdefghijklmnopqrstuvwxyz")
+}
+
+// trimIssueNumber removes leading zeros from an issue number, so "012" is "12" and "000" is "0".
+func trimIssueNumber(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	for len(number) > 1 && number[0] == '0' && number[1] >= '0' && number[1] <= '9' {
+		number = number[1:]
+	}
+	return sign + number
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want *ComicInfo
+	}{
+		{"Series v02 #012 - Title (2019).cbz", &ComicInfo{Series: "Series", Volume: 2, Number: "12", Title: "Title", Year: 2019}},
+		{"comics/Series Name #1.5.cbr", &ComicInfo{Series: "Series Name", Number: "1.5"}},
+		{"Saga_055_(2018-05)_(Digital)_(Zone-Empire).cbr", &ComicInfo{Series: "Saga", Number: "55", Year: 2018, Month: 5}},
+		{"Batman - The Long Halloween 001 (1996).cb7", &ComicInfo{Series: "Batman - The Long Halloween", Number: "1", Year: 1996}},
+		{"Series Vol. 3 (2001).cbt", &ComicInfo{Series: "Series", Volume: 3, Year: 2001}},
+		{"Series #000.zip", &ComicInfo{Series: "Series", Number: "0"}},
+		{"Series #AU.zip", &ComicInfo{Series: "Series", Number: "AU"}},
+		{"Series v1.5", &ComicInfo{Series: "Series v1.5"}},
+		{"One Shot [Scanner].rar", &ComicInfo{Series: "One Shot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseFileName(tt.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFileName() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
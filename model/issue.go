@@ -0,0 +1,140 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IssueNumber is a parsed ComicInfo Number, such as "1", "1.5", "-1", "½", "1AU" or "Annual 1".
+// It is made of an optional prefix, an optional number and an optional suffix.
+type IssueNumber struct {
+	raw string
+
+	// start and end are the position of the number in raw. They are equal if there is no number.
+	start, end int
+
+	value float64
+}
+
+// issueNumberPattern finds the first number, which may be negative, decimal or a vulgar fraction.
+var issueNumberPattern = regexp.MustCompile(`-?(?:\d+(?:\.\d+)?[½¼¾]?|[½¼¾])`)
+
+var fractions = map[string]float64{"½": 0.5, "¼": 0.25, "¾": 0.75}
+
+// ParseIssueNumber parses an issue number. Any string can be parsed, so there is no error.
+func ParseIssueNumber(s string) IssueNumber {
+	n := IssueNumber{raw: strings.TrimSpace(s)}
+
+	loc := issueNumberPattern.FindStringIndex(n.raw)
+	if loc == nil {
+		n.start, n.end = len(n.raw), len(n.raw)
+		return n
+	}
+	n.start, n.end = loc[0], loc[1]
+
+	// A hyphen joined to a word, as in "Annual-1", separates rather than negates
+	if n.raw[n.start] == '-' && n.start > 0 && n.raw[n.start-1] != ' ' {
+		n.start++
+	}
+
+	number := n.raw[n.start:n.end]
+	for f, v := range fractions {
+		if strings.HasSuffix(number, f) {
+			number = strings.TrimSuffix(number, f)
+			n.value = v
+		}
+	}
+	if number != "" && number != "-" {
+		whole, _ := strconv.ParseFloat(number, 64)
+		if whole < 0 || strings.HasPrefix(number, "-") {
+			n.value = whole - n.value
+		} else {
+			n.value += whole
+		}
+	} else if number == "-" {
+		n.value = -n.value
+	}
+
+	return n
+}
+
+// IssueNumber returns the parsed Number of a ComicInfo.
+func (c *ComicInfo) IssueNumber() IssueNumber {
+	return ParseIssueNumber(c.Number)
+}
+
+// Prefix is the text before the number, such as "Annual".
+func (n IssueNumber) Prefix() string {
+	return strings.Trim(n.raw[:n.start], " #-")
+}
+
+// Suffix is the text after the number, such as "AU".
+func (n IssueNumber) Suffix() string {
+	return strings.TrimSpace(n.raw[n.end:])
+}
+
+// HasNumber reports whether the issue number contains a number.
+func (n IssueNumber) HasNumber() bool {
+	return n.start < n.end
+}
+
+// Value is the value of the number, or 0 if there is none.
+func (n IssueNumber) Value() float64 {
+	return n.value
+}
+
+func (n IssueNumber) String() string {
+	return n.raw
+}
+
+// Compare orders issue numbers for reading, returning -1, 0 or +1.
+// Issues are grouped by prefix, with unprefixed issues first, then ordered by number,
+// with issues without a number last, then by suffix, with no suffix first.
+// Only issue numbers with the same text compare as equal, so the ordering is total.
+func (n IssueNumber) Compare(other IssueNumber) int {
+	if c := strings.Compare(strings.ToLower(n.Prefix()), strings.ToLower(other.Prefix())); c != 0 {
+		return c
+	}
+	if n.HasNumber() != other.HasNumber() {
+		if n.HasNumber() {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case n.value < other.value:
+		return -1
+	case n.value > other.value:
+		return 1
+	}
+	if c := strings.Compare(strings.ToLower(n.Suffix()), strings.ToLower(other.Suffix())); c != 0 {
+		return c
+	}
+	return strings.Compare(n.raw, other.raw)
+}
+
+// Less reports whether n is before other in reading order.
+func (n IssueNumber) Less(other IssueNumber) bool {
+	return n.Compare(other) < 0
+}
+
+// Format returns the issue number with the whole part of the number zero-padded to at least pad digits,
+// so "1.5" with a pad of 3 is "001.5". Prefix and suffix are kept as they are.
+func (n IssueNumber) Format(pad int) string {
+	if !n.HasNumber() {
+		return n.raw
+	}
+
+	number := n.raw[n.start:n.end]
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	digits := len(number) - len(strings.TrimLeft(number, "0123456789"))
+	if digits < pad {
+		number = strings.Repeat("0", pad-digits) + number
+	}
+
+	return n.raw[:n.start] + sign + number + n.raw[n.end:]
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseIssueNumber(t *testing.T) {
+	tests := []struct {
+		s         string
+		prefix    string
+		hasNumber bool
+		value     float64
+		suffix    string
+	}{
+		{"", "", false, 0, ""},
+		{"1", "", true, 1, ""},
+		{" 10 ", "", true, 10, ""},
+		{"1.5", "", true, 1.5, ""},
+		{"0", "", true, 0, ""},
+		{"-1", "", true, -1, ""},
+		{"½", "", true, 0.5, ""},
+		{"1½", "", true, 1.5, ""},
+		{"-½", "", true, -0.5, ""},
+		{"1AU", "", true, 1, "AU"},
+		{"Annual 1", "Annual", true, 1, ""},
+		{"Annual-2", "Annual", true, 2, ""},
+		{"#5", "", true, 5, ""},
+		{"Special", "Special", false, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			n := ParseIssueNumber(tt.s)
+			if n.Prefix() != tt.prefix || n.HasNumber() != tt.hasNumber || n.Value() != tt.value || n.Suffix() != tt.suffix {
+				t.Errorf("ParseIssueNumber() = %q %v %v %q, want %q %v %v %q",
+					n.Prefix(), n.HasNumber(), n.Value(), n.Suffix(), tt.prefix, tt.hasNumber, tt.value, tt.suffix)
+			}
+		})
+	}
+}
+
+func TestIssueNumber_Compare(t *testing.T) {
+	want := []string{"-1", "0", "½", "1", "1AU", "1.5", "2", "10", "Annual 1", "Annual 2", "Special"}
+
+	got := []string{"10", "Annual 2", "2", "1AU", "Special", "1", "-1", "Annual 1", "1.5", "½", "0"}
+	sort.Slice(got, func(i, j int) bool {
+		return ParseIssueNumber(got[i]).Less(ParseIssueNumber(got[j]))
+	})
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted = %v, want %v", got, want)
+	}
+
+	if c := ParseIssueNumber("1").Compare(ParseIssueNumber("01")); c == 0 {
+		t.Errorf("Compare() of different text should not be equal")
+	}
+}
+
+func TestIssueNumber_Format(t *testing.T) {
+	tests := []struct {
+		s    string
+		pad  int
+		want string
+	}{
+		{"1", 3, "001"},
+		{"1.5", 3, "001.5"},
+		{"-1", 2, "-01"},
+		{"½", 2, "00½"},
+		{"1234", 3, "1234"},
+		{"Annual 1AU", 2, "Annual 01AU"},
+		{"Special", 3, "Special"},
+		{"7", 0, "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := ParseIssueNumber(tt.s).Format(tt.pad); got != tt.want {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Warning describes something lenient decoding changed to read a malformed ComicInfo.xml.
+type Warning struct {
+	// Field is the path of the value changed, such as Manga or Pages[3].Type. Blank for the whole document.
+	Field   string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Field == "" {
+		return w.Message
+	}
+	return w.Field + ": " + w.Message
+}
+
+// UnmarshalLenient reads a ComicInfo.xml file like Unmarshal, but normalises common mistakes
+// instead of failing. See DecodeLenient.
+func UnmarshalLenient(file *zip.File) (*ComicInfo, []Warning, error) {
+	if !IsComicInfo(file.Name) {
+		return nil, nil, fmt.Errorf("invalid file name: %v", file.Name)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open zip %s for reading: %w", file.Name, err)
+	}
+	defer r.Close()
+
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+	}
+
+	info, warnings, err := DecodeLenient(bs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to XML unmarshal %s: %w", file.Name, err)
+	}
+	return info, warnings, nil
+}
+
+// DecodeLenient decodes a ComicInfo.xml document that real-world tools have written badly.
+// It accepts a byte order mark, ISO-8859-1 and Windows-1252 encodings, element and attribute names
+// in the wrong case, enum values in the wrong case, true/false for Yes/No values, whitespace around numbers
+// and whole numbers such as "12.0" in integer fields. Numbers that can't be read are dropped.
+// Every change is returned as a Warning. Values are not validated, so use Validate for strict checks.
+func DecodeLenient(bs []byte) (*ComicInfo, []Warning, error) {
+	l := lenient{}
+	bs = l.fixEncoding(bs)
+
+	doc := rawDocument{}
+	d := xml.NewDecoder(bytes.NewReader(bs))
+	d.CharsetReader = l.charsetReader
+	if err := d.Decode(&doc); err != nil {
+		return nil, l.warnings, err
+	}
+
+	var elements []Element
+	for _, e := range doc.Elements {
+		if l.fixElement(&e) {
+			elements = append(elements, e)
+		}
+	}
+	doc.Elements = elements
+
+	bs, err := xml.Marshal(doc)
+	if err != nil {
+		return nil, l.warnings, err
+	}
+
+	info := ComicInfo{}
+	if err = xml.Unmarshal(bs, &info); err != nil {
+		return nil, l.warnings, err
+	}
+	info.Version = info.RequiredVersion()
+
+	return &info, l.warnings, nil
+}
+
+// rawDocument is a ComicInfo.xml document before its elements are decoded.
+type rawDocument struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Elements []Element  `xml:",any"`
+}
+
+// UnmarshalXML reads the elements with the namespace prefixes they were read with, as ComicInfo does,
+// so the document is written back with the same names.
+func (doc *rawDocument) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	prefixes := namespacePrefixes(nil, start.Attr)
+	doc.XMLName = prefixedName(start.Name, prefixes)
+	doc.Attrs = prefixedAttrs(start.Attr, prefixes)
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			e, err := decodeElement(d, t, prefixes)
+			if err != nil {
+				return err
+			}
+			doc.Elements = append(doc.Elements, e)
+		}
+	}
+}
+
+// lenient collects the warnings of a single DecodeLenient call.
+type lenient struct {
+	warnings []Warning
+}
+
+func (l *lenient) warn(field string, format string, args ...any) {
+	l.warnings = append(l.warnings, Warning{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// fixEncoding removes a byte order mark, and converts undeclared non UTF-8 text from Windows-1252.
+func (l *lenient) fixEncoding(bs []byte) []byte {
+	if bytes.HasPrefix(bs, []byte("\xef\xbb\xbf")) {
+		bs = bs[3:]
+		l.warn("", "removed UTF-8 byte order mark")
+	}
+
+	if !utf8.Valid(bs) && !declaresCharset(bs) {
+		bs = []byte(decodeWindows1252(bs))
+		l.warn("", "converted invalid UTF-8 text from Windows-1252")
+	}
+	return bs
+}
+
+// declaresCharset reports whether a document declares an encoding other than UTF-8.
+func declaresCharset(bs []byte) bool {
+	if !bytes.HasPrefix(bs, []byte("<?xml")) {
+		return false
+	}
+	end := bytes.Index(bs, []byte("?>"))
+	if end < 0 {
+		return false
+	}
+	_, after, found := strings.Cut(string(bs[:end]), "encoding=")
+	return found && !strings.Contains(strings.ToLower(after), "utf-8")
+}
+
+// charsetReader is an xml.Decoder CharsetReader for ISO-8859-1 and Windows-1252.
+// Windows-1252 is a superset of the printable ISO-8859-1 characters, so both are read as Windows-1252.
+func (l *lenient) charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "windows-1252", "cp1252", "us-ascii", "ascii":
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %v", charset)
+	}
+
+	bs, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	l.warn("", "converted from %s encoding", charset)
+	return strings.NewReader(decodeWindows1252(bs)), nil
+}
+
+// windows1252 are the characters of Windows-1252 bytes 0x80 to 0x9f. Undefined bytes map to the C1 control character.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008d', 'Ž', '\u008f',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009d', 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(bs []byte) string {
+	b := strings.Builder{}
+	for _, c := range bs {
+		if c >= 0x80 && c <= 0x9f {
+			b.WriteRune(windows1252[c-0x80])
+		} else {
+			b.WriteRune(rune(c))
+		}
+	}
+	return b.String()
+}
+
+// fixElement normalises a top level element. Returns false if the element should be dropped.
+func (l *lenient) fixElement(e *Element) bool {
+	name := e.XMLName.Local
+	if strings.EqualFold(name, "Pages") {
+		if name != "Pages" {
+			l.warn("Pages", "renamed element %s", name)
+			e.XMLName.Local = "Pages"
+		}
+		l.fixPages(e)
+		return true
+	}
+
+	f, err := LookupField(name)
+	if err != nil || f.Kind == KindDate {
+		return true // unknown elements, and those named like virtual fields, are kept as they are
+	}
+	if f.Name != name {
+		l.warn(f.Name, "renamed element %s", name)
+		e.XMLName.Local = f.Name
+	}
+
+	text, err := innerText(e.Content)
+	if err != nil {
+		return true // not a simple value, so leave it for strict decoding to report
+	}
+
+	var fixed string
+	var ok bool
+	switch f.Kind {
+	case KindInt:
+		fixed, ok = l.fixInt(f.Name, text)
+	case KindFloat:
+		fixed, ok = l.fixFloat(f.Name, text)
+	case KindEnum:
+		fixed, ok = l.fixEnum(f.Name, text, f.Values), true
+	default:
+		return true
+	}
+	if !ok {
+		return false
+	}
+	if fixed != text {
+		e.Content = escapeText(fixed)
+	}
+	return true
+}
+
+// pageAttrs are the attribute names of a Page element.
+var pageAttrs = []string{"Image", "Type", "DoublePage", "ImageSize", "Key", "Bookmark", "ImageWidth", "ImageHeight"}
+
+// fixPages normalises the attributes of the Page elements in a Pages element.
+func (l *lenient) fixPages(e *Element) {
+	pages := struct {
+		Children []Element `xml:",any"`
+	}{}
+	if err := xml.Unmarshal([]byte("<Pages>"+e.Content+"</Pages>"), &pages); err != nil {
+		return // leave it for strict decoding to report
+	}
+
+	b := strings.Builder{}
+	index := 0
+	for i := range pages.Children {
+		page := &pages.Children[i]
+		if strings.EqualFold(page.XMLName.Local, "Page") {
+			path := fmt.Sprintf("Pages[%d]", index)
+			index++
+			if page.XMLName.Local != "Page" {
+				l.warn(path, "renamed element %s", page.XMLName.Local)
+				page.XMLName.Local = "Page"
+			}
+			page.Attrs = l.fixPageAttrs(path, page.Attrs)
+		}
+		bs, err := xml.Marshal(page)
+		if err != nil {
+			return
+		}
+		b.Write(bs)
+	}
+	e.Content = b.String()
+}
+
+func (l *lenient) fixPageAttrs(path string, attrs []xml.Attr) []xml.Attr {
+	var result []xml.Attr
+	for _, a := range prefixedAttrs(attrs, namespacePrefixes(nil, attrs)) {
+		name := a.Name.Local
+		for _, known := range pageAttrs {
+			if strings.EqualFold(name, known) && name != known {
+				l.warn(path+"."+known, "renamed attribute %s", name)
+				a.Name.Local = known
+			}
+		}
+
+		ok := true
+		field := path + "." + a.Name.Local
+		switch a.Name.Local {
+		case "Image", "ImageSize", "ImageWidth", "ImageHeight":
+			a.Value, ok = l.fixInt(field, a.Value)
+		case "Type":
+			a.Value = l.fixEnum(field, a.Value, comicPageTypeValues)
+		case "DoublePage":
+			a.Value, ok = l.fixBool(field, a.Value)
+		}
+		if ok {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// fixInt normalises an integer. Returns false if it isn't a number.
+func (l *lenient) fixInt(field, s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		l.warn(field, "dropped empty value")
+		return "", false
+	}
+	if _, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		if trimmed != s {
+			l.warn(field, "trimmed whitespace from %q", s)
+		}
+		return trimmed, true
+	}
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		fixed := strconv.FormatInt(int64(f), 10)
+		l.warn(field, "normalised %q to %s", s, fixed)
+		return fixed, true
+	}
+	l.warn(field, "dropped invalid number %q", s)
+	return "", false
+}
+
+// fixFloat normalises a decimal number, accepting a comma as the decimal separator. Returns false if it isn't a number.
+func (l *lenient) fixFloat(field, s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		l.warn(field, "dropped empty value")
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(trimmed, 64); err == nil {
+		if trimmed != s {
+			l.warn(field, "trimmed whitespace from %q", s)
+		}
+		return trimmed, true
+	}
+	if fixed := strings.Replace(trimmed, ",", ".", 1); fixed != trimmed {
+		if _, err := strconv.ParseFloat(fixed, 64); err == nil {
+			l.warn(field, "normalised %q to %s", s, fixed)
+			return fixed, true
+		}
+	}
+	l.warn(field, "dropped invalid number %q", s)
+	return "", false
+}
+
+// fixEnum matches a value to the allowed values ignoring case, and reads true and false as Yes and No.
+// Values that still don't match are kept for Validate to report.
+func (l *lenient) fixEnum(field, s string, values []string) string {
+	trimmed := strings.TrimSpace(s)
+	fixed := trimmed
+	for _, v := range values {
+		if strings.EqualFold(v, trimmed) {
+			fixed = v
+			break
+		}
+	}
+	if validateEnum(fixed, values) != nil && validateEnum("Yes", values) == nil {
+		if b, err := strconv.ParseBool(trimmed); err == nil {
+			fixed = "No"
+			if b {
+				fixed = "Yes"
+			}
+		}
+	}
+	if fixed != s {
+		l.warn(field, "normalised %q to %q", s, fixed)
+	}
+	return fixed
+}
+
+// fixBool normalises a boolean, accepting Yes and No. Returns false if it isn't a boolean.
+func (l *lenient) fixBool(field, s string) (string, bool) {
+	trimmed := strings.TrimSpace(s)
+	fixed := strings.ToLower(trimmed)
+	switch fixed {
+	case "true", "false":
+	case "yes", "1":
+		fixed = "true"
+	case "no", "0", "":
+		fixed = "false"
+	default:
+		l.warn(field, "dropped invalid boolean %q", s)
+		return "", false
+	}
+	if fixed != s {
+		l.warn(field, "normalised %q to %s", s, fixed)
+	}
+	return fixed, true
+}
+
+// innerText returns the text of inner XML that has no child elements.
+func innerText(content string) (string, error) {
+	v := struct {
+		Text  string    `xml:",chardata"`
+		Child []Element `xml:",any"`
+	}{}
+	if err := xml.Unmarshal([]byte("<v>"+content+"</v>"), &v); err != nil {
+		return "", err
+	}
+	if len(v.Child) > 0 {
+		return "", fmt.Errorf("element has child elements")
+	}
+	return v.Text, nil
+}
+
+func escapeText(s string) string {
+	b := strings.Builder{}
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"github.com/blissd/cbz/internal/ziptest"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeLenient(t *testing.T) {
+	tests := []struct {
+		name         string
+		doc          string
+		want         ComicInfo
+		wantWarnings []Warning
+	}{
+		{
+			"Valid",
+			`<ComicInfo><Title>Title</Title><Manga>Yes</Manga></ComicInfo>`,
+			ComicInfo{Title: "Title", Manga: "Yes"},
+			nil,
+		},
+		{
+			"Byte order mark",
+			"\xef\xbb\xbf<?xml version=\"1.0\" encoding=\"utf-8\"?><ComicInfo><Title>Title</Title></ComicInfo>",
+			ComicInfo{Title: "Title"},
+			[]Warning{{Message: "removed UTF-8 byte order mark"}},
+		},
+		{
+			"Windows-1252",
+			"<?xml version=\"1.0\" encoding=\"windows-1252\"?><ComicInfo><Title>Caf\xe9 \x93Noir\x94</Title></ComicInfo>",
+			ComicInfo{Title: "Café “Noir”"},
+			[]Warning{{Message: "converted from windows-1252 encoding"}},
+		},
+		{
+			"Undeclared Latin-1",
+			"<ComicInfo><Title>Caf\xe9</Title></ComicInfo>",
+			ComicInfo{Title: "Café"},
+			[]Warning{{Message: "converted invalid UTF-8 text from Windows-1252"}},
+		},
+		{
+			"Enum case",
+			`<ComicInfo><Manga>yes</Manga><AgeRating>mature 17+</AgeRating></ComicInfo>`,
+			ComicInfo{Manga: "Yes", AgeRating: "Mature 17+"},
+			[]Warning{{"Manga", `normalised "yes" to "Yes"`}, {"AgeRating", `normalised "mature 17+" to "Mature 17+"`}},
+		},
+		{
+			"Boolean enum",
+			`<ComicInfo><BlackAndWhite>true</BlackAndWhite></ComicInfo>`,
+			ComicInfo{BlackAndWhite: "Yes"},
+			[]Warning{{"BlackAndWhite", `normalised "true" to "Yes"`}},
+		},
+		{
+			"Numbers",
+			`<ComicInfo><Volume> 2 </Volume><Count>12.0</Count><Year>unknown</Year><CommunityRating>4,5</CommunityRating></ComicInfo>`,
+			ComicInfo{Volume: 2, Count: 12, CommunityRating: 4.5},
+			[]Warning{
+				{"Volume", `trimmed whitespace from " 2 "`},
+				{"Count", `normalised "12.0" to 12`},
+				{"Year", `dropped invalid number "unknown"`},
+				{"CommunityRating", `normalised "4,5" to 4.5`},
+			},
+		},
+		{
+			"Element case",
+			`<ComicInfo><series>Series</series><pages><page image="0" type="frontcover" DoublePage="Yes"/></pages></ComicInfo>`,
+			ComicInfo{Series: "Series", Pages: []ComicPageInfo{{Image: 0, Type: "FrontCover", DoublePage: true}}},
+			[]Warning{
+				{"Series", "renamed element series"},
+				{"Pages", "renamed element pages"},
+				{"Pages[0]", "renamed element page"},
+				{"Pages[0].Image", "renamed attribute image"},
+				{"Pages[0].Type", "renamed attribute type"},
+				{"Pages[0].Type", `normalised "frontcover" to "FrontCover"`},
+				{"Pages[0].DoublePage", `normalised "Yes" to true`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := DecodeLenient([]byte(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Version = SchemaVersion20
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("DecodeLenient() got = %+v, want %+v", *got, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("DecodeLenient() warnings = %v, want %v", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestDecodeLenient_strictStillFails(t *testing.T) {
+	doc := `<ComicInfo><Count>12.0</Count><Manga>yes</Manga></ComicInfo>`
+
+	if _, err := Unmarshal(ziptest.File(t, ComicInfoXmlName, doc)); err == nil {
+		t.Errorf("Unmarshal() should fail on a decimal Count")
+	}
+
+	info, _, err := DecodeLenient([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = info.Validate(); err != nil {
+		t.Errorf("Validate() of normalised values = %v", err)
+	}
+}
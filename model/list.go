@@ -0,0 +1,123 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// List is the entries of a multi-valued ComicInfo field, such as Writer or Characters.
+type List []string
+
+// ParseList splits a comma separated value into trimmed entries, dropping blanks and duplicates.
+// Duplicates are matched ignoring case, keeping the first spelling.
+func ParseList(s string) List {
+	return parseList(s, ",")
+}
+
+// parseList splits s on any of the separator characters.
+func parseList(s string, separators string) List {
+	var l List
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		l = l.Add(v)
+	}
+	return l
+}
+
+// String joins the entries with commas.
+func (l List) String() string {
+	return strings.Join(l, ", ")
+}
+
+// Index returns the position of an entry, ignoring case, or -1 if it isn't present.
+func (l List) Index(v string) int {
+	v = strings.TrimSpace(v)
+	for i, e := range l {
+		if strings.EqualFold(e, v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Contains reports whether an entry is present, ignoring case.
+func (l List) Contains(v string) bool {
+	return l.Index(v) >= 0
+}
+
+// Add appends entries that aren't already present.
+func (l List) Add(values ...string) List {
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" && !l.Contains(v) {
+			l = append(l, v)
+		}
+	}
+	return l
+}
+
+// Remove removes an entry, ignoring case. It is an error if the entry isn't present.
+func (l List) Remove(v string) (List, error) {
+	i := l.Index(v)
+	if i < 0 {
+		return l, fmt.Errorf("no entry %q", v)
+	}
+	return append(l[:i:i], l[i+1:]...), nil
+}
+
+// Replace replaces an entry, ignoring case, keeping its position. It is an error if the entry isn't present.
+// If the replacement is already present the old entry is removed instead.
+func (l List) Replace(old, new string) (List, error) {
+	i := l.Index(old)
+	if i < 0 {
+		return l, fmt.Errorf("no entry %q", old)
+	}
+	new = strings.TrimSpace(new)
+	if j := l.Index(new); new == "" || (j >= 0 && j != i) {
+		return l.Remove(old)
+	}
+	replaced := append(List(nil), l...)
+	replaced[i] = new
+	return replaced, nil
+}
+
+// List returns the entries of a multi-valued field.
+func (f *Field) List(c *ComicInfo) (List, error) {
+	if !f.Multi {
+		return nil, fmt.Errorf("field %s is not multi-valued", f.Name)
+	}
+	s, _ := f.get(c).(string)
+	if f.Name == "Web" {
+		// v2.1 separates URLs with whitespace. URLs can contain commas, in paths and queries, but not whitespace.
+		return parseList(s, " \t\r\n"), nil
+	}
+	return ParseList(s), nil
+}
+
+// SetList replaces the entries of a multi-valued field.
+func (f *Field) SetList(c *ComicInfo, l List) error {
+	if !f.Multi {
+		return fmt.Errorf("field %s is not multi-valued", f.Name)
+	}
+	if f.Name == "Web" {
+		return f.Set(c, strings.Join(l, " "))
+	}
+	return f.Set(c, l.String())
+}
+
+// List returns the entries of a named multi-valued field, such as Writer or Characters.
+func (c *ComicInfo) List(name string) (List, error) {
+	f, err := LookupField(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.List(c)
+}
+
+// SetList replaces the entries of a named multi-valued field.
+func (c *ComicInfo) SetList(name string, l List) error {
+	f, err := LookupField(name)
+	if err != nil {
+		return err
+	}
+	return f.SetList(c, l)
+}
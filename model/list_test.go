@@ -0,0 +1,87 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want List
+	}{
+		{"Blank", "", nil},
+		{"Single", "Alan Moore", List{"Alan Moore"}},
+		{"Trimmed", " Alan Moore ,Dave Gibbons, ", List{"Alan Moore", "Dave Gibbons"}},
+		{"Duplicates", "Batman, Robin, batman", List{"Batman", "Robin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseList(tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList_edits(t *testing.T) {
+	l := List{"Batman", "Robin"}
+
+	if got := l.Add("Alfred", "robin").String(); got != "Batman, Robin, Alfred" {
+		t.Errorf("Add() = %v", got)
+	}
+
+	got, err := l.Remove("ROBIN")
+	if err != nil || got.String() != "Batman" {
+		t.Errorf("Remove() = %v, %v", got, err)
+	}
+	if _, err = l.Remove("Joker"); err == nil {
+		t.Errorf("Remove() of a missing entry should fail")
+	}
+
+	got, err = l.Replace("Robin", "Nightwing")
+	if err != nil || got.String() != "Batman, Nightwing" {
+		t.Errorf("Replace() = %v, %v", got, err)
+	}
+	got, err = l.Replace("Robin", "batman")
+	if err != nil || got.String() != "Batman" {
+		t.Errorf("Replace() with an existing entry = %v, %v", got, err)
+	}
+
+	if l.String() != "Batman, Robin" {
+		t.Errorf("edits changed the original list: %v", l)
+	}
+}
+
+func TestComicInfo_List(t *testing.T) {
+	info := ComicInfo{Characters: "Batman,Robin , Batman", Web: "https://a.example https://b.example/a,b?c=1,2\nhttps://c.example"}
+
+	got, err := info.List("Characters")
+	if err != nil || !reflect.DeepEqual(got, List{"Batman", "Robin"}) {
+		t.Errorf("List(Characters) = %v, %v", got, err)
+	}
+
+	if err = info.SetList("Characters", got.Add("Alfred")); err != nil {
+		t.Fatal(err)
+	}
+	if info.Characters != "Batman, Robin, Alfred" {
+		t.Errorf("SetList(Characters) = %v", info.Characters)
+	}
+
+	got, err = info.List("Web")
+	if want := (List{"https://a.example", "https://b.example/a,b?c=1,2", "https://c.example"}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("List(Web) = %v, %v, want %v", got, err, want)
+	}
+
+	if err = info.SetList("Web", got.Add("https://d.example/x,y")); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://a.example https://b.example/a,b?c=1,2 https://c.example https://d.example/x,y"; info.Web != want {
+		t.Errorf("SetList(Web) = %v, want %v", info.Web, want)
+	}
+
+	if _, err = info.List("Volume"); err == nil {
+		t.Errorf("List(Volume) should fail as Volume is not multi-valued")
+	}
+}
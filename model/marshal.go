@@ -0,0 +1,219 @@
+package model
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// XMLHeader is the declaration at the start of documents written by MarshalDocument.
+const XMLHeader = `<?xml version="1.0" encoding="utf-8"?>` + "\n"
+
+// indent is the indentation of each level of nested elements.
+const indent = "  "
+
+// Marshal returns the canonical ComicInfo.xml document of a ComicInfo.
+// The same metadata is always written as the same bytes.
+func Marshal(c *ComicInfo) ([]byte, error) {
+	return MarshalDocument(c)
+}
+
+// MarshalDocument marshals a value as a canonical XML document: an XML declaration,
+// one element per line indented by two spaces, self-closing empty elements and a final new line.
+// Whitespace between known elements is replaced by the same layout, but the inner XML of unknown elements,
+// such as ComicInfo.Unknown, is written unchanged.
+func MarshalDocument(v any) ([]byte, error) {
+	bs, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := readTokens(bs, unknownNames(v))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read marshalled XML: %w", err)
+	}
+
+	b := bytes.Buffer{}
+	b.WriteString(XMLHeader)
+
+	depth := 0
+	for i := 0; i < len(tokens); i++ {
+		switch t := tokens[i].(type) {
+		case xml.StartElement:
+			writeIndent(&b, depth)
+			writeStart(&b, t)
+			next, after := tokenAt(tokens, i+1), tokenAt(tokens, i+2)
+			if _, ok := next.(xml.EndElement); ok {
+				b.WriteString(" />\n")
+				i++
+				continue
+			}
+			if text, ok := next.(xml.CharData); ok {
+				if end, ok := after.(xml.EndElement); ok {
+					b.WriteString(">")
+					textEscaper.WriteString(&b, string(text))
+					fmt.Fprintf(&b, "</%s>\n", name(end.Name))
+					i += 2
+					continue
+				}
+			}
+			b.WriteString(">\n")
+			depth++
+		case verbatimElement:
+			writeIndent(&b, depth)
+			writeStart(&b, t.start)
+			if len(t.inner) == 0 {
+				b.WriteString(" />\n")
+				continue
+			}
+			b.WriteString(">")
+			b.Write(t.inner)
+			fmt.Fprintf(&b, "</%s>\n", name(t.start.Name))
+		case xml.EndElement:
+			depth--
+			writeIndent(&b, depth)
+			fmt.Fprintf(&b, "</%s>\n", name(t.Name))
+		case xml.CharData:
+			writeIndent(&b, depth)
+			textEscaper.WriteString(&b, string(t))
+			b.WriteString("\n")
+		case xml.Comment:
+			writeIndent(&b, depth)
+			fmt.Fprintf(&b, "<!--%s-->\n", t)
+		case xml.ProcInst:
+			writeIndent(&b, depth)
+			fmt.Fprintf(&b, "<?%s %s?>\n", t.Target, t.Inst)
+		case xml.Directive:
+			writeIndent(&b, depth)
+			fmt.Fprintf(&b, "<!%s>\n", t)
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// verbatimElement is an element of the root element whose inner XML is written unchanged.
+type verbatimElement struct {
+	start xml.StartElement
+	inner []byte
+}
+
+// unknownNames are the names of the unknown elements of a document, held in an Unknown []Element field
+// such as ComicInfo.Unknown.
+func unknownNames(v any) map[string]bool {
+	names := map[string]bool{}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return names
+	}
+	if f := rv.FieldByName("Unknown"); f.IsValid() {
+		if elements, ok := f.Interface().([]Element); ok {
+			for _, e := range elements {
+				names[name(e.XMLName)] = true
+			}
+		}
+	}
+	return names
+}
+
+// readTokens reads the raw tokens of an XML document, dropping whitespace between elements.
+// Whitespace that is the only content of an element is kept.
+// Elements of the root element named in verbatim are read as a verbatimElement, with their inner XML unchanged.
+func readTokens(bs []byte, verbatim map[string]bool) ([]xml.Token, error) {
+	var all []xml.Token
+	d := xml.NewDecoder(bytes.NewReader(bs))
+	depth := 0
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if depth == 1 && verbatim[name(t.Name)] {
+				e, err := readVerbatim(d, bs, t)
+				if err != nil {
+					return nil, err
+				}
+				all = append(all, e)
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		all = append(all, xml.CopyToken(t))
+	}
+
+	var tokens []xml.Token
+	for i, t := range all {
+		if text, ok := t.(xml.CharData); ok && len(bytes.TrimSpace(text)) == 0 {
+			_, afterStart := tokenAt(all, i-1).(xml.StartElement)
+			_, beforeEnd := tokenAt(all, i+1).(xml.EndElement)
+			if !afterStart || !beforeEnd {
+				continue
+			}
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+// readVerbatim reads the rest of an element whose start has just been read, keeping its inner XML unchanged.
+func readVerbatim(d *xml.Decoder, bs []byte, start xml.StartElement) (verbatimElement, error) {
+	from := d.InputOffset()
+	for depth := 1; ; {
+		to := d.InputOffset()
+		t, err := d.RawToken()
+		if err != nil {
+			return verbatimElement{}, err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth--; depth == 0 {
+				return verbatimElement{start: xml.CopyToken(start).(xml.StartElement), inner: bs[from:to]}, nil
+			}
+		}
+	}
+}
+
+func tokenAt(tokens []xml.Token, i int) xml.Token {
+	if i < 0 || i >= len(tokens) {
+		return nil
+	}
+	return tokens[i]
+}
+
+func writeIndent(b *bytes.Buffer, depth int) {
+	b.WriteString(strings.Repeat(indent, depth))
+}
+
+func writeStart(b *bytes.Buffer, t xml.StartElement) {
+	b.WriteString("<")
+	b.WriteString(name(t.Name))
+	for _, a := range t.Attr {
+		fmt.Fprintf(b, ` %s="`, name(a.Name))
+		attrEscaper.WriteString(b, a.Value)
+		b.WriteString(`"`)
+	}
+}
+
+// name formats a raw token name, where Space is the prefix rather than the namespace URL.
+func name(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "\n", "&#xA;", "\r", "&#xD;", "\t", "&#x9;")
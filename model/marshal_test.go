@@ -0,0 +1,89 @@
+package model
+
+import (
+	"encoding/xml"
+	"github.com/blissd/cbz/internal/ziptest"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	info := &ComicInfo{
+		Title:   "Tom & Jerry <1>",
+		Summary: "Line one\nLine two",
+		Unknown: []Element{
+			{XMLName: xml.Name{Local: "Empty"}},
+			{XMLName: xml.Name{Local: "Nested"}, Attrs: []xml.Attr{{Name: xml.Name{Local: "a"}, Value: `"q"`}}, Content: "<Child>x</Child>\n\t\t<Child/>"},
+		},
+	}
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema">
+  <Title>Tom &amp; Jerry &lt;1&gt;</Title>
+  <Summary>Line one
+Line two</Summary>
+  <Empty />
+  <Nested a="&quot;q&quot;"><Child>x</Child>
+		<Child/></Nested>
+</ComicInfo>
+`
+
+	got, err := Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("want: %v, got: %v", want, string(got))
+	}
+
+	// Reading and writing again gives the same bytes
+	read, err := Unmarshal(ziptest.File(t, ComicInfoXmlName, string(got)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Marshal(read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != want {
+		t.Fatalf("want: %v, got: %v", want, string(again))
+	}
+	if read.Attrs != nil {
+		t.Errorf("standard namespace attributes should not be kept: %v", read.Attrs)
+	}
+}
+
+func TestMarshal_mixedContent(t *testing.T) {
+	input := `<ComicInfo>
+ <Title>Saga</Title>
+ <Foo>a<b>x</b>c</Foo>
+ <Bar>
+	<b> x </b>  <!-- note -->
+ </Bar>
+</ComicInfo>`
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema">
+  <Title>Saga</Title>
+  <Foo>a<b>x</b>c</Foo>
+  <Bar>
+	<b> x </b>  <!-- note -->
+ </Bar>
+</ComicInfo>
+`
+
+	doc := input
+	for i := 0; i < 2; i++ {
+		info, err := Unmarshal(ziptest.File(t, ComicInfoXmlName, doc))
+		if err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+		got, err := Marshal(info)
+		if err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+		if string(got) != want {
+			t.Fatalf("write %d: want: %v, got: %v", i, want, string(got))
+		}
+		doc = string(got)
+	}
+}
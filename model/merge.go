@@ -0,0 +1,258 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MergePolicy is how ComicInfo.Merge combines two ComicInfo values.
+type MergePolicy int
+
+const (
+	// MergeFillEmpty only sets fields that are empty.
+	MergeFillEmpty MergePolicy = iota
+
+	// MergeOverwrite sets every field that is not empty in the other ComicInfo.
+	MergeOverwrite
+
+	// MergeLists adds the entries of multi-valued fields, such as Writer or Characters,
+	// to the existing entries. Other fields are merged as with MergeFillEmpty.
+	MergeLists
+)
+
+func (p MergePolicy) String() string {
+	switch p {
+	case MergeFillEmpty:
+		return "fill"
+	case MergeOverwrite:
+		return "overwrite"
+	case MergeLists:
+		return "lists"
+	}
+	return fmt.Sprintf("MergePolicy(%d)", int(p))
+}
+
+// ParseMergePolicy parses a merge policy name: fill, overwrite or lists.
+func ParseMergePolicy(s string) (MergePolicy, error) {
+	for _, p := range []MergePolicy{MergeFillEmpty, MergeOverwrite, MergeLists} {
+		if strings.EqualFold(s, p.String()) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown merge policy: %v", s)
+}
+
+// Clone returns a deep copy of a ComicInfo.
+func (c *ComicInfo) Clone() *ComicInfo {
+	clone := *c
+	clone.Attrs = cloneAttrs(c.Attrs)
+
+	if c.ElementAttrs != nil {
+		clone.ElementAttrs = make(map[string]Attrs, len(c.ElementAttrs))
+		for name, attrs := range c.ElementAttrs {
+			clone.ElementAttrs[name] = cloneAttrs(attrs)
+		}
+	}
+
+	if c.Pages != nil {
+		clone.Pages = make([]ComicPageInfo, len(c.Pages))
+		for i, p := range c.Pages {
+			p.Attrs = cloneAttrs(p.Attrs)
+			clone.Pages[i] = p
+		}
+	}
+
+	if c.Unknown != nil {
+		clone.Unknown = make([]Element, len(c.Unknown))
+		for i, e := range c.Unknown {
+			e.Attrs = cloneAttrs(e.Attrs)
+			clone.Unknown[i] = e
+		}
+	}
+
+	return &clone
+}
+
+func cloneAttrs(attrs []xml.Attr) []xml.Attr {
+	if attrs == nil {
+		return nil
+	}
+	return append([]xml.Attr{}, attrs...)
+}
+
+// Merge combines the fields of other into c according to a policy.
+// Pages are taken from other if c has none, or with MergeOverwrite if other has any.
+// Unknown elements of other are added, replacing elements of the same name only with MergeOverwrite,
+// as are attributes of the ComicInfo element, such as the namespace declarations unknown elements use.
+func (c *ComicInfo) Merge(other *ComicInfo, policy MergePolicy) error {
+	for _, f := range fields {
+		if f.IsZero(other) {
+			continue
+		}
+
+		switch {
+		case policy == MergeLists && f.Multi:
+			l, err := f.List(c)
+			if err != nil {
+				return err
+			}
+			entries, err := f.List(other)
+			if err != nil {
+				return err
+			}
+			if err = f.SetList(c, l.Add(entries...)); err != nil {
+				return err
+			}
+		case policy == MergeOverwrite || f.IsZero(c):
+			if err := f.Set(c, f.Get(other)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(other.Pages) > 0 && (len(c.Pages) == 0 || policy == MergeOverwrite) {
+		c.Pages = other.Clone().Pages
+	}
+
+	for _, e := range other.Clone().Unknown {
+		i := c.unknownIndex(e.XMLName)
+		switch {
+		case i < 0:
+			c.Unknown = append(c.Unknown, e)
+		case policy == MergeOverwrite:
+			c.Unknown[i] = e
+		}
+	}
+
+	for _, a := range other.Attrs {
+		if _, ok := attrValue(c.Attrs, a.Name); !ok {
+			c.Attrs = append(c.Attrs, a)
+		}
+	}
+
+	return nil
+}
+
+// unknownIndex returns the index of the first unknown element with a name, or -1 if there is none.
+func (c *ComicInfo) unknownIndex(name xml.Name) int {
+	for i, e := range c.Unknown {
+		if e.XMLName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Change is a difference in a single value between two ComicInfo values.
+// An added or removed page has a nil Old or New value, otherwise values are those of Field.Get,
+// a page attribute or the inner XML of an unknown element.
+type Change struct {
+	// Field is the path of the value, such as Title, Pages[3].Type or Pages[4].
+	Field string
+	Old   any
+	New   any
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, formatChangeValue(c.Old), formatChangeValue(c.New))
+}
+
+func formatChangeValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return strconv.Quote(v)
+	case ComicPageType:
+		return strconv.Quote(string(v))
+	case ComicPageInfo:
+		return fmt.Sprintf("Page %d", v.Image)
+	}
+	return fmt.Sprint(v)
+}
+
+// Diff returns the changes needed to turn c into other, in XML document order.
+func (c *ComicInfo) Diff(other *ComicInfo) []Change {
+	var changes []Change
+
+	for _, f := range fields {
+		if old, new := f.Get(c), f.Get(other); old != new {
+			changes = append(changes, Change{Field: f.Name, Old: old, New: new})
+		}
+	}
+
+	for i := 0; i < len(c.Pages) || i < len(other.Pages); i++ {
+		path := fmt.Sprintf("Pages[%d]", i)
+		switch {
+		case i >= len(other.Pages):
+			changes = append(changes, Change{Field: path, Old: c.Pages[i]})
+		case i >= len(c.Pages):
+			changes = append(changes, Change{Field: path, New: other.Pages[i]})
+		default:
+			changes = append(changes, diffPage(path, &c.Pages[i], &other.Pages[i])...)
+		}
+	}
+
+	for _, e := range c.Unknown {
+		if other.unknownIndex(e.XMLName) < 0 {
+			changes = append(changes, Change{Field: e.XMLName.Local, Old: e.Content})
+		}
+	}
+	for _, e := range other.Unknown {
+		i := c.unknownIndex(e.XMLName)
+		switch {
+		case i < 0:
+			changes = append(changes, Change{Field: e.XMLName.Local, New: e.Content})
+		case c.Unknown[i].Content != e.Content || !reflect.DeepEqual(c.Unknown[i].Attrs, e.Attrs):
+			changes = append(changes, Change{Field: e.XMLName.Local, Old: c.Unknown[i].Content, New: e.Content})
+		}
+	}
+
+	return changes
+}
+
+// diffPage returns the changed attributes of a page.
+func diffPage(path string, old, new *ComicPageInfo) []Change {
+	var changes []Change
+	add := func(attr string, o, n any) {
+		if o != n {
+			changes = append(changes, Change{Field: path + "." + attr, Old: o, New: n})
+		}
+	}
+
+	add("Image", old.Image, new.Image)
+	add("Type", old.Type, new.Type)
+	add("DoublePage", old.DoublePage, new.DoublePage)
+	add("ImageSize", old.ImageSize, new.ImageSize)
+	add("Key", old.Key, new.Key)
+	add("Bookmark", old.Bookmark, new.Bookmark)
+	add("ImageWidth", old.ImageWidth, new.ImageWidth)
+	add("ImageHeight", old.ImageHeight, new.ImageHeight)
+
+	for _, a := range old.Attrs {
+		if v, ok := attrValue(new.Attrs, a.Name); !ok {
+			changes = append(changes, Change{Field: path + "." + a.Name.Local, Old: a.Value})
+		} else if v != a.Value {
+			changes = append(changes, Change{Field: path + "." + a.Name.Local, Old: a.Value, New: v})
+		}
+	}
+	for _, a := range new.Attrs {
+		if _, ok := attrValue(old.Attrs, a.Name); !ok {
+			changes = append(changes, Change{Field: path + "." + a.Name.Local, New: a.Value})
+		}
+	}
+
+	return changes
+}
+
+func attrValue(attrs []xml.Attr, name xml.Name) (string, bool) {
+	for _, a := range attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
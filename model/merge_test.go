@@ -0,0 +1,92 @@
+package model
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestComicInfo_Merge(t *testing.T) {
+	existing := ComicInfo{Title: "Old", Writer: "Alan Moore", Pages: []ComicPageInfo{{Image: 0}}}
+	other := ComicInfo{
+		Title:   "New",
+		Series:  "Watchmen",
+		Writer:  "Dave Gibbons, alan moore",
+		Pages:   []ComicPageInfo{{Image: 0, Type: "FrontCover"}},
+		Unknown: []Element{{XMLName: xml.Name{Local: "Custom"}, Content: "x"}},
+		Attrs:   Attrs{{Name: xml.Name{Local: "xmlns:foo"}, Value: "urn:foo"}},
+	}
+	unknown := []Element{{XMLName: xml.Name{Local: "Custom"}, Content: "x"}}
+	attrs := Attrs{{Name: xml.Name{Local: "xmlns:foo"}, Value: "urn:foo"}}
+
+	tests := []struct {
+		name   string
+		policy MergePolicy
+		want   ComicInfo
+	}{
+		{"Fill empty", MergeFillEmpty, ComicInfo{Title: "Old", Series: "Watchmen", Writer: "Alan Moore", Pages: []ComicPageInfo{{Image: 0}}, Unknown: unknown, Attrs: attrs}},
+		{"Overwrite", MergeOverwrite, ComicInfo{Title: "New", Series: "Watchmen", Writer: "Dave Gibbons, alan moore", Pages: []ComicPageInfo{{Image: 0, Type: "FrontCover"}}, Unknown: unknown, Attrs: attrs}},
+		{"Lists", MergeLists, ComicInfo{Title: "Old", Series: "Watchmen", Writer: "Alan Moore, Dave Gibbons", Pages: []ComicPageInfo{{Image: 0}}, Unknown: unknown, Attrs: attrs}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := existing.Clone()
+			if err := info.Merge(&other, tt.policy); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*info, tt.want) {
+				t.Errorf("Merge() = %v, want %v", *info, tt.want)
+			}
+		})
+	}
+
+	if existing.Title != "Old" || existing.Pages[0].Type != "" {
+		t.Errorf("Merge() changed the original of a clone")
+	}
+}
+
+func TestParseMergePolicy(t *testing.T) {
+	for _, p := range []MergePolicy{MergeFillEmpty, MergeOverwrite, MergeLists} {
+		if got, err := ParseMergePolicy(p.String()); err != nil || got != p {
+			t.Errorf("ParseMergePolicy(%v) = %v, %v", p, got, err)
+		}
+	}
+	if _, err := ParseMergePolicy("union"); err == nil {
+		t.Errorf("ParseMergePolicy() of an unknown policy should fail")
+	}
+}
+
+func TestComicInfo_Diff(t *testing.T) {
+	old := ComicInfo{
+		Title: "Old",
+		Year:  1986,
+		Pages: []ComicPageInfo{{Image: 0}, {Image: 1}},
+	}
+	new := ComicInfo{
+		Title:   "New",
+		Pages:   []ComicPageInfo{{Image: 0, Type: "FrontCover", Attrs: []xml.Attr{{Name: xml.Name{Local: "Extra"}, Value: "1"}}}, {Image: 1}, {Image: 2}},
+		Unknown: []Element{{XMLName: xml.Name{Local: "Custom"}, Content: "x"}},
+	}
+
+	want := []Change{
+		{Field: "Title", Old: "Old", New: "New"},
+		{Field: "Year", Old: int64(1986), New: int64(0)},
+		{Field: "Pages[0].Type", Old: ComicPageType(""), New: ComicPageType("FrontCover")},
+		{Field: "Pages[0].Extra", New: "1"},
+		{Field: "Pages[2]", New: new.Pages[2]},
+		{Field: "Custom", New: "x"},
+	}
+
+	got := old.Diff(&new)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+
+	if got := new.Diff(new.Clone()); got != nil {
+		t.Errorf("Diff() of a clone = %v, want none", got)
+	}
+
+	if got, want := want[0].String(), `Title: "Old" -> "New"`; got != want {
+		t.Errorf("Change.String() = %v, want %v", got, want)
+	}
+}
@@ -4,249 +4,565 @@ import (
 	"archive/zip"
 	"encoding/xml"
 	"fmt"
-	"io"
-	"strconv"
+	"reflect"
 	"strings"
 )
 
 // Models the ComicInfo.xml schema file.
-// Schema is currently at version 2.0: https://github.com/anansi-project/comicinfo/blob/main/schema/v2.0/ComicInfo.xsd
+// Schema versions 2.0 and 2.1 are supported:
+// https://github.com/anansi-project/comicinfo/blob/main/schema/v2.0/ComicInfo.xsd
+// https://github.com/anansi-project/comicinfo/blob/main/drafts/v2.1/ComicInfo.xsd
 
 const ComicInfoXmlName = "ComicInfo.xml"
 
+// SchemaVersion is a version of the ComicInfo.xml schema.
+type SchemaVersion string
+
+const (
+	SchemaVersion20 SchemaVersion = "2.0"
+	SchemaVersion21 SchemaVersion = "2.1"
+)
+
+// schemaVersions lists supported schema versions from oldest to newest.
+var schemaVersions = []SchemaVersion{SchemaVersion20, SchemaVersion21}
+
+// ParseSchemaVersion parses a schema version such as "2.0" or "2.1".
+func ParseSchemaVersion(s string) (SchemaVersion, error) {
+	v := SchemaVersion(s)
+	if err := v.validate(); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+func (v SchemaVersion) validate() error {
+	allowed := make([]string, len(schemaVersions))
+	for i, sv := range schemaVersions {
+		allowed[i] = string(sv)
+	}
+	return validateEnum(string(v), allowed)
+}
+
+// index is the position of v in schemaVersions, or -1 for an unknown version.
+func (v SchemaVersion) index() int {
+	for i, sv := range schemaVersions {
+		if sv == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// Before reports whether v is an older schema version than o.
+func (v SchemaVersion) Before(o SchemaVersion) bool {
+	return v.index() < o.index()
+}
+
 type YesNo string
 
+var yesNoValues = []string{
+	"Unknown",
+	"No",
+	"Yes",
+}
+
 func (v YesNo) validate() error {
-	switch v {
-	case "":
-	case "Unknown":
-	case "No":
-	case "Yes":
-	default:
-		return fmt.Errorf("invalid value for YesNo: %v", v)
-	}
-	return nil
+	return validateEnum(string(v), yesNoValues)
 }
 
 type AgeRating string
 
+// The order of age ratings in the XSD is somewhat random.
+// This order is taken from the Kavita source code: https://github.com/Kareadita/Kavita/blob/develop/API/Entities/Enums/AgeRating.cs
+var ageRatingValues = []string{
+	"Unknown",
+	"Rating Pending",
+	"Early Childhood",
+	"Everyone",
+	"G",
+	"Everyone 10+",
+	"PG",
+	"Kids to Adults",
+	"Teen",
+	"MA15+",
+	"Mature 17+",
+	"M",
+	"R18+",
+	"Adults Only 18+",
+	"X18+",
+}
+
 func (v AgeRating) validate() error {
-	// The order of age ratings in the XSD is somewhat random.
-	// This order is taken from the Kavita source code: https://github.com/Kareadita/Kavita/blob/develop/API/Entities/Enums/AgeRating.cs
-	switch v {
-	case "":
-	case "Unknown":
-	case "Rating Pending":
-	case "Early Childhood":
-	case "Everyone":
-	case "G":
-	case "Everyone 10+":
-	case "PG":
-	case "Kids to Adults":
-	case "Teen":
-	case "MA15+":
-	case "Mature 17+":
-	case "M":
-	case "R18+":
-	case "Adults Only 18+":
-	case "X18+":
-	default:
-		return fmt.Errorf("invalid value for AgeRating: %v", v)
-	}
-	return nil
+	return validateEnum(string(v), ageRatingValues)
 }
 
 type Manga string
 
+var mangaValues = []string{
+	"Unknown",
+	"No",
+	"Yes",
+	"YesAndRightToLeft",
+}
+
 func (v Manga) validate() error {
-	switch v {
-	case "":
-	case "Unknown":
-	case "No":
-	case "Yes":
-	case "YesAndRightToLeft":
-	default:
-		return fmt.Errorf("invalid value for Manga: %v", v)
-	}
-	return nil
+	return validateEnum(string(v), mangaValues)
 }
 
 type Rating float64
 
 type ComicPageType string
 
+var comicPageTypeValues = []string{
+	"FrontCover",
+	"InnerCover",
+	"Roundup",
+	"Story",
+	"Advertisement",
+	"Editorial",
+	"Letters",
+	"Preview",
+	"BackCover",
+	"Other",
+	"Deleted",
+}
+
 func (v ComicPageType) validate() error {
-	switch v {
-	case "":
-	case "FrontCover":
-	case "InnerCover":
-	case "Roundup":
-	case "Story":
-	case "Advertisement":
-	case "Editorial":
-	case "Letters":
-	case "Preview":
-	case "BackCover":
-	case "Other":
-	case "Deleted":
-	default:
-		return fmt.Errorf("invalid value for ComicPageType: %v", v)
-	}
-	return nil
+	return validateEnum(string(v), comicPageTypeValues)
 }
 
 type ComicPageInfo struct {
-	Image       int           `xml:",attr"`
-	Type        ComicPageType `xml:",attr,omitempty"`
-	DoublePage  bool          `xml:",attr,omitempty"`
-	ImageSize   int64         `xml:",attr,omitempty"`
-	Key         string        `xml:",attr,omitempty"`
-	Bookmark    string        `xml:",attr,omitempty"`
-	ImageWidth  int           `xml:",attr,omitempty"`
-	ImageHeight int           `xml:",attr,omitempty"`
+	Image       int           `xml:",attr" json:"Image" yaml:"Image"`
+	Type        ComicPageType `xml:",attr,omitempty" json:"Type,omitempty" yaml:"Type,omitempty"`
+	DoublePage  bool          `xml:",attr,omitempty" json:"DoublePage,omitempty" yaml:"DoublePage,omitempty"`
+	ImageSize   int64         `xml:",attr,omitempty" json:"ImageSize,omitempty" yaml:"ImageSize,omitempty"`
+	Key         string        `xml:",attr,omitempty" json:"Key,omitempty" yaml:"Key,omitempty"`
+	Bookmark    string        `xml:",attr,omitempty" json:"Bookmark,omitempty" yaml:"Bookmark,omitempty"`
+	ImageWidth  int           `xml:",attr,omitempty" json:"ImageWidth,omitempty" yaml:"ImageWidth,omitempty"`
+	ImageHeight int           `xml:",attr,omitempty" json:"ImageHeight,omitempty" yaml:"ImageHeight,omitempty"`
+
+	// Attrs are attributes not modelled by ComicPageInfo, kept so they can be written back unchanged.
+	Attrs Attrs `xml:",any,attr" json:"Attrs,omitempty" yaml:"Attrs,omitempty"`
 }
 
+// ArrayOfComicPageInfo is the Pages element. It marshals its Page children itself
+// so that, unlike a "Pages>Page" field, no empty Pages element is written when there are no pages.
 type ArrayOfComicPageInfo []ComicPageInfo
 
-type ComicInfo struct {
-	Title               string               `xml:",omitempty"`
-	Series              string               `xml:",omitempty"`
-	Number              string               `xml:",omitempty"`
-	Count               int64                `xml:",omitempty"`
-	Volume              int64                `xml:",omitempty"`
-	AlternativeSeries   string               `xml:",omitempty"`
-	AlternativeNumber   string               `xml:",omitempty"`
-	AlternativeCount    int64                `xml:",omitempty"`
-	Summary             string               `xml:",omitempty"`
-	Notes               string               `xml:",omitempty"`
-	Year                int64                `xml:",omitempty"`
-	Month               int64                `xml:",omitempty"`
-	Day                 int64                `xml:",omitempty"`
-	Writer              string               `xml:",omitempty"`
-	Penciller           string               `xml:",omitempty"`
-	Inker               string               `xml:",omitempty"`
-	Colorist            string               `xml:",omitempty"`
-	Letterer            string               `xml:",omitempty"`
-	CoverArtist         string               `xml:",omitempty"`
-	Editor              string               `xml:",omitempty"`
-	Translator          string               `xml:",omitempty"`
-	Publisher           string               `xml:",omitempty"`
-	Imprint             string               `xml:",omitempty"`
-	Genre               string               `xml:",omitempty"`
-	Web                 string               `xml:",omitempty"`
-	PageCount           int64                `xml:",omitempty"`
-	LanguageISO         string               `xml:",omitempty"`
-	Format              string               `xml:",omitempty"`
-	BlackAndWhite       YesNo                `xml:",omitempty"`
-	Manga               Manga                `xml:",omitempty"`
-	Characters          string               `xml:",omitempty"`
-	Teams               string               `xml:",omitempty"`
-	Locations           string               `xml:",omitempty"`
-	ScanInformation     string               `xml:",omitempty"`
-	StoryArc            string               `xml:",omitempty"`
-	SeriesGroup         string               `xml:",omitempty"`
-	AgeRating           AgeRating            `xml:",omitempty"`
-	Pages               ArrayOfComicPageInfo `xml:"Pages>Page,omitempty"`
-	CommunityRating     Rating               `xml:",omitempty"`
-	MainCharacterOrTeam string               `xml:",omitempty"`
-	Review              string               `xml:",omitempty"`
+type arrayOfComicPageInfo struct {
+	Page []ComicPageInfo
 }
 
-func (c *ComicInfo) String() string {
-	marshal, err := xml.MarshalIndent(c, "", " ")
-	if err != nil {
-		return "<invalid ComicInfo.xml>"
+func (a *ArrayOfComicPageInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	pages := arrayOfComicPageInfo{}
+	if err := d.DecodeElement(&pages, &start); err != nil {
+		return err
 	}
+	*a = append(*a, pages.Page...)
+	return nil
+}
+
+func (a ArrayOfComicPageInfo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(arrayOfComicPageInfo{a}, start)
+}
+
+type ComicInfo struct {
+	Title               string               `xml:",omitempty" json:"Title,omitempty" yaml:"Title,omitempty"`
+	Series              string               `xml:",omitempty" json:"Series,omitempty" yaml:"Series,omitempty"`
+	Number              string               `xml:",omitempty" json:"Number,omitempty" yaml:"Number,omitempty"`
+	Count               int64                `xml:",omitempty" json:"Count,omitempty" yaml:"Count,omitempty"`
+	Volume              int64                `xml:",omitempty" json:"Volume,omitempty" yaml:"Volume,omitempty"`
+	AlternativeSeries   string               `xml:"AlternateSeries,omitempty" json:"AlternateSeries,omitempty" yaml:"AlternateSeries,omitempty"`
+	AlternativeNumber   string               `xml:"AlternateNumber,omitempty" json:"AlternateNumber,omitempty" yaml:"AlternateNumber,omitempty"`
+	AlternativeCount    int64                `xml:"AlternateCount,omitempty" json:"AlternateCount,omitempty" yaml:"AlternateCount,omitempty"`
+	Summary             string               `xml:",omitempty" json:"Summary,omitempty" yaml:"Summary,omitempty"`
+	Notes               string               `xml:",omitempty" json:"Notes,omitempty" yaml:"Notes,omitempty"`
+	Year                int64                `xml:",omitempty" json:"Year,omitempty" yaml:"Year,omitempty"`
+	Month               int64                `xml:",omitempty" json:"Month,omitempty" yaml:"Month,omitempty"`
+	Day                 int64                `xml:",omitempty" json:"Day,omitempty" yaml:"Day,omitempty"`
+	Writer              string               `xml:",omitempty" json:"Writer,omitempty" yaml:"Writer,omitempty"`
+	Penciller           string               `xml:",omitempty" json:"Penciller,omitempty" yaml:"Penciller,omitempty"`
+	Inker               string               `xml:",omitempty" json:"Inker,omitempty" yaml:"Inker,omitempty"`
+	Colorist            string               `xml:",omitempty" json:"Colorist,omitempty" yaml:"Colorist,omitempty"`
+	Letterer            string               `xml:",omitempty" json:"Letterer,omitempty" yaml:"Letterer,omitempty"`
+	CoverArtist         string               `xml:",omitempty" json:"CoverArtist,omitempty" yaml:"CoverArtist,omitempty"`
+	Editor              string               `xml:",omitempty" json:"Editor,omitempty" yaml:"Editor,omitempty"`
+	Translator          string               `xml:",omitempty" json:"Translator,omitempty" yaml:"Translator,omitempty"`
+	Publisher           string               `xml:",omitempty" json:"Publisher,omitempty" yaml:"Publisher,omitempty"`
+	Imprint             string               `xml:",omitempty" json:"Imprint,omitempty" yaml:"Imprint,omitempty"`
+	Genre               string               `xml:",omitempty" json:"Genre,omitempty" yaml:"Genre,omitempty"`
+	Tags                string               `xml:",omitempty" json:"Tags,omitempty" yaml:"Tags,omitempty"` // v2.1
+	Web                 string               `xml:",omitempty" json:"Web,omitempty" yaml:"Web,omitempty"`
+	PageCount           int64                `xml:",omitempty" json:"PageCount,omitempty" yaml:"PageCount,omitempty"`
+	LanguageISO         string               `xml:",omitempty" json:"LanguageISO,omitempty" yaml:"LanguageISO,omitempty"`
+	Format              string               `xml:",omitempty" json:"Format,omitempty" yaml:"Format,omitempty"`
+	BlackAndWhite       YesNo                `xml:",omitempty" json:"BlackAndWhite,omitempty" yaml:"BlackAndWhite,omitempty"`
+	Manga               Manga                `xml:",omitempty" json:"Manga,omitempty" yaml:"Manga,omitempty"`
+	Characters          string               `xml:",omitempty" json:"Characters,omitempty" yaml:"Characters,omitempty"`
+	Teams               string               `xml:",omitempty" json:"Teams,omitempty" yaml:"Teams,omitempty"`
+	Locations           string               `xml:",omitempty" json:"Locations,omitempty" yaml:"Locations,omitempty"`
+	ScanInformation     string               `xml:",omitempty" json:"ScanInformation,omitempty" yaml:"ScanInformation,omitempty"`
+	StoryArc            string               `xml:",omitempty" json:"StoryArc,omitempty" yaml:"StoryArc,omitempty"`
+	StoryArcNumber      string               `xml:",omitempty" json:"StoryArcNumber,omitempty" yaml:"StoryArcNumber,omitempty"` // v2.1
+	SeriesGroup         string               `xml:",omitempty" json:"SeriesGroup,omitempty" yaml:"SeriesGroup,omitempty"`
+	AgeRating           AgeRating            `xml:",omitempty" json:"AgeRating,omitempty" yaml:"AgeRating,omitempty"`
+	Pages               ArrayOfComicPageInfo `xml:"Pages,omitempty" json:"Pages,omitempty" yaml:"Pages,omitempty"`
+	CommunityRating     Rating               `xml:",omitempty" json:"CommunityRating,omitempty" yaml:"CommunityRating,omitempty"`
+	MainCharacterOrTeam string               `xml:",omitempty" json:"MainCharacterOrTeam,omitempty" yaml:"MainCharacterOrTeam,omitempty"`
+	Review              string               `xml:",omitempty" json:"Review,omitempty" yaml:"Review,omitempty"`
+	GTIN                string               `xml:",omitempty" json:"GTIN,omitempty" yaml:"GTIN,omitempty"` // v2.1
+
+	// LocalizedSeries, SeriesSort and TitleSort are not part of the XSD, but are read by Kavita and Komga.
+	// They are treated as v2.1 fields.
+	LocalizedSeries string `xml:",omitempty" json:"LocalizedSeries,omitempty" yaml:"LocalizedSeries,omitempty"`
+	SeriesSort      string `xml:",omitempty" json:"SeriesSort,omitempty" yaml:"SeriesSort,omitempty"`
+	TitleSort       string `xml:",omitempty" json:"TitleSort,omitempty" yaml:"TitleSort,omitempty"`
+
+	// Unknown are elements not modelled by ComicInfo, in the order they were read.
+	// They are written back unchanged, each before the known element it preceded when read.
+	Unknown []Element `xml:",any" json:"Unknown,omitempty" yaml:"Unknown,omitempty"`
+
+	// Attrs are the attributes of the ComicInfo element, such as namespace declarations.
+	Attrs Attrs `xml:"-" json:"Attrs,omitempty" yaml:"Attrs,omitempty"`
+
+	// ElementAttrs are the attributes of known elements, such as xsi:nil, by element name.
+	ElementAttrs map[string]Attrs `xml:"-" json:"ElementAttrs,omitempty" yaml:"ElementAttrs,omitempty"`
+
+	// Version is the schema version the ComicInfo is validated against: Validate rejects fields introduced after it.
+	// ComicInfo.xml files don't declare a version, so readers set the oldest version that holds the populated fields.
+	// It doesn't change the XML written, which is the same for every version.
+	// An empty Version places no restriction on which fields may be populated.
+	Version SchemaVersion `xml:"-" json:"-" yaml:"-"`
+}
+
+// Element is an XML element that isn't modelled by ComicInfo.
+// Its attributes and content are kept verbatim so the element can be written back unchanged.
+// Namespaced names are kept with the prefix they were read with, such as "foo:Ext".
+type Element struct {
+	XMLName xml.Name
+	Attrs   Attrs  `xml:",any,attr"`
+	Content string `xml:",innerxml"`
+
+	// Before is the name of the known element this element preceded when read.
+	// If it is "", as for elements read after all known elements, it is written after all known elements.
+	Before string `xml:"-"`
+}
 
-	s := string(marshal)
-	s = strings.ReplaceAll(s, "></Page>", " />")
-	s = strings.ReplaceAll(s, " <Pages></Pages>\n", "")
+// Attrs are XML attributes that aren't modelled, kept so they can be written back unchanged.
+// Namespaced names are kept with the prefix they were read with, such as "xsi:nil".
+type Attrs []xml.Attr
 
-	return s
+// comicInfo is ComicInfo without the custom XML (un)marshalling, to avoid infinite recursion.
+type comicInfo ComicInfo
+
+// elementField is a field of ComicInfo written as an XML element.
+type elementField struct {
+	name  string
+	index int
 }
 
-func (c *ComicInfo) Validate() error {
-	if err := c.AgeRating.validate(); err != nil {
-		return fmt.Errorf("invalid value for AgeRating: %w", err)
+// elementFields are the fields of ComicInfo written as XML elements, in the order they are written.
+var elementFields = func() []elementField {
+	var result []elementField
+	t := reflect.TypeOf(comicInfo{})
+	for i := 0; i < t.NumField(); i++ {
+		name, opts, _ := strings.Cut(t.Field(i).Tag.Get("xml"), ",")
+		if name == "-" || strings.Contains(opts, "any") {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		result = append(result, elementField{name: name, index: i})
 	}
-	if err := c.BlackAndWhite.validate(); err != nil {
-		return fmt.Errorf("invalid value for BlackAndWhite: %w", err)
+	return result
+}()
+
+// legacyElements are the names earlier versions of this tool wrote for AlternateSeries, AlternateNumber and AlternateCount.
+// They are read if the element with the schema name is empty, and written back with the schema name.
+var legacyElements = map[string]string{
+	"AlternativeSeries": "AlternateSeries",
+	"AlternativeNumber": "AlternateNumber",
+	"AlternativeCount":  "AlternateCount",
+}
+
+// knownElement returns the field an element is read into, if it is a known element.
+func (c *ComicInfo) knownElement(name xml.Name) (elementField, bool) {
+	local := name.Local
+	legacy, isLegacy := legacyElements[local]
+	if isLegacy {
+		local = legacy
 	}
-	if err := c.Manga.validate(); err != nil {
-		return fmt.Errorf("invalid value for Manga: %w", err)
+	for _, f := range elementFields {
+		if f.name != local {
+			continue
+		}
+		if isLegacy && !reflect.ValueOf(c).Elem().Field(f.index).IsZero() {
+			return elementField{}, false
+		}
+		return f, true
 	}
+	return elementField{}, false
+}
+
+// UnmarshalXML reads the known elements into their fields, and keeps the others, with their position, as Unknown.
+func (c *ComicInfo) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	prefixes := namespacePrefixes(nil, start.Attr)
+	c.Attrs = withoutNamespaceAttrs(prefixedAttrs(start.Attr, prefixes))
 
-	for _, p := range c.Pages {
-		if err := p.Type.validate(); err != nil {
-			return fmt.Errorf("invalid value for Pages.Type: %w", err)
+	v := reflect.ValueOf((*comicInfo)(c)).Elem()
+	pending := len(c.Unknown)
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
 		}
-	}
+		switch t := t.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			f, known := c.knownElement(t.Name)
+			if !known {
+				e, err := decodeElement(d, t, prefixes)
+				if err != nil {
+					return err
+				}
+				c.Unknown = append(c.Unknown, e)
+				continue
+			}
 
-	return nil
+			if err = d.DecodeElement(v.Field(f.index).Addr().Interface(), &t); err != nil {
+				return err
+			}
+			elementPrefixes := namespacePrefixes(prefixes, t.Attr)
+			if attrs := prefixedAttrs(t.Attr, elementPrefixes); attrs != nil {
+				if c.ElementAttrs == nil {
+					c.ElementAttrs = map[string]Attrs{}
+				}
+				c.ElementAttrs[f.name] = attrs
+			}
+			if f.name == "Pages" {
+				for i := range c.Pages {
+					c.Pages[i].Attrs = prefixedAttrs(c.Pages[i].Attrs, namespacePrefixes(elementPrefixes, c.Pages[i].Attrs))
+				}
+			}
+			for ; pending < len(c.Unknown); pending++ {
+				c.Unknown[pending].Before = f.name
+			}
+		}
+	}
 }
 
-func Unmarshal(file *zip.File) (*ComicInfo, error) {
-	if file.Name != ComicInfoXmlName {
-		return nil, fmt.Errorf("invalid file name: %v", file.Name)
+// decodeElement reads an element as an Element, with the namespace prefixes it was read with.
+func decodeElement(d *xml.Decoder, start xml.StartElement, parent map[string]string) (Element, error) {
+	inner := struct {
+		Content string `xml:",innerxml"`
+	}{}
+	if err := d.DecodeElement(&inner, &start); err != nil {
+		return Element{}, err
 	}
-	r, err := file.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open zip %s for reading: %w", file.Name, err)
+	prefixes := namespacePrefixes(parent, start.Attr)
+	return Element{
+		XMLName: prefixedName(start.Name, prefixes),
+		Attrs:   prefixedAttrs(start.Attr, prefixes),
+		Content: inner.Content,
+	}, nil
+}
+
+// MarshalXML writes the standard xmlns:xsi and xmlns:xsd namespace declarations, followed by any other attributes read.
+// Known elements are written in schema order, and each unknown element before the known element it preceded.
+func (c ComicInfo) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr, namespaceAttrs...)
+	start.Attr = append(start.Attr, c.Attrs...)
+	if err := e.EncodeToken(start); err != nil {
+		return err
 	}
-	defer r.Close()
 
-	bs, err := io.ReadAll(r)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+	known := map[string]bool{}
+	v := reflect.ValueOf(comicInfo(c))
+	for _, f := range elementFields {
+		known[f.name] = true
+		if err := c.encodeUnknown(e, func(before string) bool { return before == f.name }); err != nil {
+			return err
+		}
+		if err := encodeField(e, f.name, v.Field(f.index), c.ElementAttrs[f.name]); err != nil {
+			return err
+		}
+	}
+	if err := c.encodeUnknown(e, func(before string) bool { return !known[before] }); err != nil {
+		return err
 	}
+	return e.EncodeToken(start.End())
+}
 
-	info := ComicInfo{}
-	err = xml.Unmarshal(bs, &info)
+// encodeField writes a known element, unless it is empty and has no attributes.
+// An empty element with attributes, such as xsi:nil, is written without content.
+func encodeField(e *xml.Encoder, name string, v reflect.Value, attrs []xml.Attr) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs}
+	empty := v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0)
+	switch {
+	case !empty:
+		return e.EncodeElement(v.Interface(), start)
+	case len(attrs) > 0:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	}
+	return nil
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to XML unmarshal %s: %w", file.Name, err)
+// encodeUnknown writes the unknown elements whose Before matches.
+func (c *ComicInfo) encodeUnknown(e *xml.Encoder, match func(before string) bool) error {
+	for _, u := range c.Unknown {
+		if match(u.Before) {
+			if err := e.Encode(u); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	return &info, nil
+// namespaceAttrs are the namespace declarations ComicRack writes on the ComicInfo element.
+var namespaceAttrs = []xml.Attr{
+	{Name: xml.Name{Local: "xmlns:xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"},
+	{Name: xml.Name{Local: "xmlns:xsd"}, Value: "http://www.w3.org/2001/XMLSchema"},
 }
 
-// intFieldNames contains ComicInfo.xml field names that have an int data type.
-var intFieldNames = []string{
-	"Count",
-	"Volume",
-	"AlternativeCount",
-	"Year",
-	"Month",
-	"Day",
-	"PageCount",
+// withoutNamespaceAttrs removes the standard namespace declarations, which are always written by MarshalXML.
+func withoutNamespaceAttrs(attrs []xml.Attr) []xml.Attr {
+	var result []xml.Attr
+	for _, a := range attrs {
+		if !containsAttr(namespaceAttrs, a) {
+			result = append(result, a)
+		}
+	}
+	return result
 }
 
-// floatFieldNames contains ComicInfo.xml field names that have a float data type.
-var floatFieldNames = []string{
-	"CommunityRating",
+func containsAttr(attrs []xml.Attr, attr xml.Attr) bool {
+	for _, a := range attrs {
+		if a == attr {
+			return true
+		}
+	}
+	return false
 }
 
-// boolFieldNames contains ComicInfo.xml field names that have a bool data type.
-var boolFieldNames = []string{
-	"DoublePage",
+// xmlNamespace is the namespace bound to the reserved "xml" prefix.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// namespacePrefixes maps namespaces to the prefixes declared for them by the attributes of an element,
+// or by its parent. A default namespace declaration maps to the "" prefix, unless the namespace also has a prefix.
+func namespacePrefixes(parent map[string]string, attrs []xml.Attr) map[string]string {
+	prefixes := map[string]string{xmlNamespace: "xml"}
+	for space, prefix := range parent {
+		prefixes[space] = prefix
+	}
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == "xmlns" {
+			if _, ok := prefixes[a.Value]; !ok {
+				prefixes[a.Value] = ""
+			}
+		}
+	}
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" {
+			prefixes[a.Value] = a.Name.Local
+		}
+	}
+	return prefixes
+}
+
+// prefixedName undoes the namespace translation xml.Decoder applies to an element name.
+// A name with an undeclared prefix already has the prefix as its namespace.
+func prefixedName(name xml.Name, prefixes map[string]string) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	prefix, ok := prefixes[name.Space]
+	if !ok {
+		prefix = name.Space
+	}
+	if prefix == "" {
+		return xml.Name{Local: name.Local}
+	}
+	return xml.Name{Local: prefix + ":" + name.Local}
 }
 
-// Convert a string representation of a value to the correct data type.
-func Convert(name string, value string) (any, error) {
-	for _, n := range intFieldNames {
-		if n == name {
-			return strconv.ParseInt(value, 10, 64)
+// prefixedAttrs undoes the namespace translation xml.Decoder applies to attribute names,
+// so attributes such as xmlns:xsi and xsi:noNamespaceSchemaLocation are written back as they were read.
+func prefixedAttrs(attrs []xml.Attr, prefixes map[string]string) []xml.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	result := make([]xml.Attr, len(attrs))
+	for i, a := range attrs {
+		switch {
+		case a.Name.Space == "xmlns":
+			a.Name = xml.Name{Local: "xmlns:" + a.Name.Local}
+		case a.Name.Space != "":
+			prefix, ok := prefixes[a.Name.Space]
+			if !ok {
+				prefix = a.Name.Space
+			}
+			if prefix != "" {
+				a.Name = xml.Name{Local: prefix + ":" + a.Name.Local}
+			}
 		}
+		result[i] = a
 	}
-	for _, n := range floatFieldNames {
-		if n == name {
-			return strconv.ParseFloat(value, 32)
+	return result
+}
+
+// newerFields returns the populated fields introduced after schema version v.
+func (c *ComicInfo) newerFields(v SchemaVersion) []*Field {
+	var newer []*Field
+	for _, f := range fields {
+		if v.Before(f.Version) && !f.IsZero(c) {
+			newer = append(newer, f)
 		}
 	}
-	for _, n := range boolFieldNames {
-		if n == name {
-			return strconv.ParseBool(value)
+	return newer
+}
+
+// RequiredVersion is the oldest schema version that can hold every populated field.
+func (c *ComicInfo) RequiredVersion() SchemaVersion {
+	required := SchemaVersion20
+	for _, f := range fields {
+		if required.Before(f.Version) && !f.IsZero(c) {
+			required = f.Version
 		}
 	}
-	return value, nil
+	return required
+}
+
+// String returns the ComicInfo.xml document written by Marshal, without the final new line.
+func (c *ComicInfo) String() string {
+	marshal, err := Marshal(c)
+	if err != nil {
+		return "<invalid ComicInfo.xml>"
+	}
+	return strings.TrimSuffix(string(marshal), "\n")
+}
+
+// Unmarshal reads a ComicInfo.xml file in a zip archive.
+// The file can be in a folder, and its name can be in any case.
+func Unmarshal(file *zip.File) (*ComicInfo, error) {
+	if !IsComicInfo(file.Name) {
+		return nil, fmt.Errorf("invalid file name: %v", file.Name)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip %s for reading: %w", file.Name, err)
+	}
+	defer r.Close()
+
+	info, err := Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", file.Name, err)
+	}
+	return info, nil
 }
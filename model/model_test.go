@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/blissd/cbz/internal/ziptest"
 	"reflect"
 	"testing"
 )
@@ -134,11 +135,17 @@ func TestConvert(t *testing.T) {
 		// float fields
 		{"CommunityRating", args{"CommunityRating", "1.5"}, 1.5, false},
 		{"Not a float", args{"CommunityRating", "not a float"}, 0.0, true},
-		// bool fields
-		{"DoublePage", args{"DoublePage", "true"}, true, false},
-		{"Not a bool", args{"DoublePage", "green"}, false, true},
+		// v2.1 fields are strings
+		{"Tags", args{"Tags", "a,b"}, "a,b", false},
+		{"GTIN", args{"GTIN", "9780785190219"}, "9780785190219", false},
 		// everything else is a string
-		{"Strings", args{"AnyRandomField", "abc"}, "abc", false},
+		{"Strings", args{"Title", "abc"}, "abc", false},
+		// field names are case-insensitive, and Go field names are accepted
+		{"Lower case", args{"pagecount", "7"}, int64(7), false},
+		{"Go field name", args{"AlternativeCount", "3"}, int64(3), false},
+		// DoublePage is a Page attribute, not a ComicInfo field
+		{"DoublePage", args{"DoublePage", "true"}, nil, true},
+		{"Unknown field", args{"AnyRandomField", "abc"}, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -171,15 +178,239 @@ func TestComicInfo_String(t *testing.T) {
 	got := info.String()
 
 	want :=
-		`<ComicInfo>
+		`<?xml version="1.0" encoding="utf-8"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema">
+  <Title>Great Comic</Title>
+  <Series>Great Series</Series>
+  <Pages>
+    <Page Image="0" Type="Story" DoublePage="true" />
+  </Pages>
+</ComicInfo>`
+
+	if want != got {
+		t.Fatalf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestParseSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       string
+		want    SchemaVersion
+		wantErr bool
+	}{
+		{"Blank", "", "", false},
+		{"2.0", "2.0", SchemaVersion20, false},
+		{"2.1", "2.1", SchemaVersion21, false},
+		{"Invalid", "3.0", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchemaVersion(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSchemaVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSchemaVersion() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComicInfo_RequiredVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		info ComicInfo
+		want SchemaVersion
+	}{
+		{"Empty", ComicInfo{}, SchemaVersion20},
+		{"v2.0 fields", ComicInfo{Title: "Title", Writer: "Someone"}, SchemaVersion20},
+		{"Translator", ComicInfo{Translator: "Someone"}, SchemaVersion21},
+		{"Tags", ComicInfo{Tags: "tag"}, SchemaVersion21},
+		{"GTIN", ComicInfo{GTIN: "9780785190219"}, SchemaVersion21},
+		{"SeriesSort", ComicInfo{SeriesSort: "Series, The"}, SchemaVersion21},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.RequiredVersion(); got != tt.want {
+				t.Errorf("RequiredVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComicInfo_Validate_version(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    ComicInfo
+		wantErr bool
+	}{
+		{"No version", ComicInfo{Tags: "tag"}, false},
+		{"v2.0 with v2.0 fields", ComicInfo{Title: "Title", Version: SchemaVersion20}, false},
+		{"v2.0 with v2.1 fields", ComicInfo{Tags: "tag", Version: SchemaVersion20}, true},
+		{"v2.1 with v2.1 fields", ComicInfo{Tags: "tag", Version: SchemaVersion21}, false},
+		{"Invalid version", ComicInfo{Version: "1.0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.info.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateGTIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		gtin    string
+		wantErr bool
+	}{
+		{"Blank", "", false},
+		{"EAN-13", "9780785190219", false},
+		{"ISBN-13 with hyphens", "978-0-7851-9021-9", false},
+		{"ISBN-10", "078519021X", false},
+		{"UPC", "759606085397", false},
+		{"Too short", "12345", true},
+		{"Letters", "97807851902AB", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGTIN(tt.gtin); (err != nil) != tt.wantErr {
+				t.Errorf("validateGTIN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_version(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+		want ComicInfo
+	}{
+		{
+			"v2.0",
+			`<ComicInfo><Series>Series</Series><AlternateSeries>Other</AlternateSeries></ComicInfo>`,
+			ComicInfo{Series: "Series", AlternativeSeries: "Other", Version: SchemaVersion20},
+		},
+		{
+			"Alternative elements",
+			`<ComicInfo><AlternativeSeries>Other &amp; More</AlternativeSeries><AlternativeNumber>2</AlternativeNumber><AlternativeCount>5</AlternativeCount></ComicInfo>`,
+			ComicInfo{AlternativeSeries: "Other & More", AlternativeNumber: "2", AlternativeCount: 5, Version: SchemaVersion20},
+		},
+		{
+			"v2.1",
+			`<ComicInfo><Series>Series</Series><Tags>a,b</Tags><GTIN>9780785190219</GTIN></ComicInfo>`,
+			ComicInfo{Series: "Series", Tags: "a,b", GTIN: "9780785190219", Version: SchemaVersion21},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Unmarshal(ziptest.File(t, ComicInfoXmlName, tt.xml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Unmarshal() got = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_preservesUnknown(t *testing.T) {
+	input := `<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:noNamespaceSchemaLocation="ComicInfo.xsd" tool="x">
  <Title>Great Comic</Title>
- <Series>Great Series</Series>
+ <Extension a="1">
+  <Nested>value</Nested>
+ </Extension>
  <Pages>
-  <Page Image="0" Type="Story" DoublePage="true" />
+  <Page Image="0" Type="Story" Custom="yes" />
  </Pages>
+ <Typo>text</Typo>
+</ComicInfo>`
+
+	info, err := Unmarshal(ziptest.File(t, ComicInfoXmlName, input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info.AgeRating = "M"
+	got := info.String()
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xsi:noNamespaceSchemaLocation="ComicInfo.xsd" tool="x">
+  <Title>Great Comic</Title>
+  <AgeRating>M</AgeRating>
+  <Extension a="1">
+  <Nested>value</Nested>
+ </Extension>
+  <Pages>
+    <Page Image="0" Type="Story" Custom="yes" />
+  </Pages>
+  <Typo>text</Typo>
 </ComicInfo>`
 
 	if want != got {
 		t.Fatalf("want: %v, got: %v", want, got)
 	}
 }
+
+func TestUnmarshal_namespacesIdempotent(t *testing.T) {
+	input := `<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:x="urn:x">
+ <First xsi:nil="true" />
+ <Title lang="en" xsi:type="string">Great Comic</Title>
+ <Series xsi:nil="true" />
+ <foo:Ext xmlns:foo="urn:foo" foo:a="1"><foo:Child /></foo:Ext>
+ <Other xmlns="urn:other" xml:lang="en">text</Other>
+ <x:Thing x:b="2" />
+ <Number>1</Number>
+ <Pages>
+  <Page Image="0" xsi:type="Page" />
+ </Pages>
+ <Last />
+</ComicInfo>`
+
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:x="urn:x">
+  <First xsi:nil="true" />
+  <Title lang="en" xsi:type="string">Great Comic</Title>
+  <Series xsi:nil="true" />
+  <foo:Ext xmlns:foo="urn:foo" foo:a="1"><foo:Child /></foo:Ext>
+  <Other xmlns="urn:other" xml:lang="en">text</Other>
+  <x:Thing x:b="2" />
+  <Number>1</Number>
+  <Pages>
+    <Page Image="0" xsi:type="Page" />
+  </Pages>
+  <Last />
+</ComicInfo>
+`
+
+	doc := input
+	for i := 0; i < 3; i++ {
+		info, err := Unmarshal(ziptest.File(t, ComicInfoXmlName, doc))
+		if err != nil {
+			t.Fatalf("read %d: %v", i, err)
+		}
+		if info.Title != "Great Comic" || info.Number != "1" {
+			t.Fatalf("read %d: got Title %q and Number %q", i, info.Title, info.Number)
+		}
+		got, err := Marshal(info)
+		if err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+		if string(got) != want {
+			t.Fatalf("write %d: want: %v, got: %v", i, want, string(got))
+		}
+		doc = string(got)
+	}
+
+	lenient, _, err := DecodeLenient([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lenient.String() + "\n"; got != want {
+		t.Fatalf("lenient: want: %v, got: %v", want, got)
+	}
+}
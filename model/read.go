@@ -0,0 +1,166 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned when there is no ComicInfo.xml file.
+var ErrNotFound = errors.New("no ComicInfo.xml file found")
+
+// AmbiguousError is returned when more than one ComicInfo.xml file could be the metadata of a comic.
+type AmbiguousError struct {
+	// Candidates are the paths of the ComicInfo.xml files.
+	Candidates []string
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("found %d ComicInfo.xml files: %s", len(e.Candidates), strings.Join(e.Candidates, ", "))
+}
+
+// IsComicInfo reports whether a path names a ComicInfo.xml file, ignoring case and directories.
+func IsComicInfo(name string) bool {
+	return strings.EqualFold(path.Base(strings.ReplaceAll(name, "\\", "/")), ComicInfoXmlName)
+}
+
+// Read decodes a ComicInfo.xml document.
+func Read(r io.Reader) (*ComicInfo, error) {
+	bs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ComicInfo.xml: %w", err)
+	}
+
+	info := ComicInfo{}
+	if err = xml.Unmarshal(bs, &info); err != nil {
+		return nil, fmt.Errorf("failed to XML unmarshal ComicInfo.xml: %w", err)
+	}
+	info.Version = info.RequiredVersion()
+
+	return &info, nil
+}
+
+// Locate finds the ComicInfo.xml file in a file system, ignoring case, at any depth.
+// The shallowest file is used, so a file at the root wins over files in folders.
+// Returns ErrNotFound if there is none, and an *AmbiguousError if the shallowest depth has more than one.
+func Locate(fsys fs.FS) (string, error) {
+	var candidates []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && IsComicInfo(p) {
+			candidates = append(candidates, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search for ComicInfo.xml: %w", err)
+	}
+
+	if len(candidates) == 0 {
+		return "", ErrNotFound
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return depth(candidates[i]) < depth(candidates[j])
+	})
+	if len(candidates) > 1 && depth(candidates[0]) == depth(candidates[1]) {
+		return "", &AmbiguousError{Candidates: candidates}
+	}
+	return candidates[0], nil
+}
+
+func depth(p string) int {
+	return strings.Count(p, "/")
+}
+
+// LocateZip finds the ComicInfo.xml file in a zip archive with Locate.
+func LocateZip(r *zip.Reader) (*zip.File, error) {
+	name, err := Locate(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range r.File {
+		if file.Name == name {
+			return file, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ReadFS finds the ComicInfo.xml file in a file system with Locate, and decodes it.
+// A *zip.Reader is a file system, so this reads a comic archive.
+func ReadFS(fsys fs.FS) (*ComicInfo, error) {
+	name, err := Locate(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+
+	info, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return info, nil
+}
+
+// ReadFile reads the ComicInfo of a path, which can be a comic archive, an unpacked comic directory, or a ComicInfo.xml file.
+// Only the ComicInfo.xml file of an archive is read, not the whole archive.
+func ReadFile(name string) (*ComicInfo, error) {
+	stat, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return ReadFS(os.DirFS(name))
+	}
+
+	isZip, err := hasZipSignature(name)
+	if err != nil {
+		return nil, err
+	}
+	if !isZip {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return Read(f)
+	}
+
+	r, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip %s: %w", name, err)
+	}
+	defer r.Close()
+	return ReadFS(&r.Reader)
+}
+
+// hasZipSignature reports whether a file starts with the "PK" signature of a zip archive.
+func hasZipSignature(name string) (bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	signature := make([]byte, 2)
+	if _, err = io.ReadFull(f, signature); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && err != io.EOF {
+		return false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return bytes.Equal(signature, []byte("PK")), nil
+}
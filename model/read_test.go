@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"github.com/blissd/cbz/internal/ziptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+const readTestXml = `<ComicInfo><Title>Title</Title></ComicInfo>`
+
+func TestLocate(t *testing.T) {
+	file := &fstest.MapFile{Data: []byte(readTestXml)}
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    string
+		wantErr error
+	}{
+		{"Root", fstest.MapFS{"ComicInfo.xml": file, "01.jpg": file}, "ComicInfo.xml", nil},
+		{"Lower case", fstest.MapFS{"comicinfo.xml": file}, "comicinfo.xml", nil},
+		{"Folder", fstest.MapFS{"Comic/ComicInfo.xml": file, "Comic/01.jpg": file}, "Comic/ComicInfo.xml", nil},
+		{"Shallowest wins", fstest.MapFS{"ComicInfo.xml": file, "extra/ComicInfo.xml": file}, "ComicInfo.xml", nil},
+		{"Ambiguous", fstest.MapFS{"a/ComicInfo.xml": file, "b/comicinfo.XML": file}, "", &AmbiguousError{}},
+		{"Not found", fstest.MapFS{"01.jpg": file}, "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Locate(tt.fsys)
+			if got != tt.want {
+				t.Errorf("Locate() = %v, want %v", got, tt.want)
+			}
+			var ambiguous *AmbiguousError
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Errorf("Locate() error = %v", err)
+			case tt.wantErr == ErrNotFound && !errors.Is(err, ErrNotFound):
+				t.Errorf("Locate() error = %v, want ErrNotFound", err)
+			case reflect.TypeOf(tt.wantErr) == reflect.TypeOf(ambiguous) && (!errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2):
+				t.Errorf("Locate() error = %v, want an AmbiguousError with 2 candidates", err)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	want := &ComicInfo{Title: "Title", Version: SchemaVersion20}
+
+	// An unpacked comic
+	unpacked := filepath.Join(dir, "unpacked", "Comic")
+	if err := os.MkdirAll(unpacked, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	xmlFile := filepath.Join(unpacked, "comicinfo.xml")
+	if err := os.WriteFile(xmlFile, []byte(readTestXml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A comic archive with ComicInfo.xml in a folder
+	archive := filepath.Join(dir, "comic.cbz")
+	ziptest.Write(t, archive, "", ziptest.Entry{Name: "Comic/ComicInfo.xml", Content: readTestXml})
+
+	for _, name := range []string{filepath.Join(dir, "unpacked"), xmlFile, archive} {
+		got, err := ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadFile(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := ReadFile(dir + "/missing"); err == nil {
+		t.Errorf("ReadFile() of a missing file should fail")
+	}
+}
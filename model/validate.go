@@ -0,0 +1,270 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// FieldError describes a field holding a value that the schema doesn't allow.
+type FieldError struct {
+	// Field is the path to the field, such as "AgeRating" or "Pages[3].Type".
+	Field string
+
+	// Value is the invalid value.
+	Value any
+
+	// Allowed lists the permitted values for an enumerated field.
+	Allowed []string
+
+	// Reason explains why the value is invalid when the field isn't an enumeration.
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	b := strings.Builder{}
+	if e.Field != "" {
+		b.WriteString(e.Field)
+		b.WriteString(": ")
+	}
+	b.WriteString(fmt.Sprintf("invalid value %q", fmt.Sprint(e.Value)))
+	if e.Reason != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Reason)
+	}
+	if len(e.Allowed) > 0 {
+		b.WriteString("; allowed values are ")
+		for i, v := range e.Allowed {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(fmt.Sprintf("%q", v))
+		}
+	}
+	return b.String()
+}
+
+// ValidationError lists every invalid field found when validating a ComicInfo.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	b := strings.Builder{}
+	if len(e.Errors) == 1 {
+		b.WriteString("1 invalid field:")
+	} else {
+		b.WriteString(fmt.Sprintf("%d invalid fields:", len(e.Errors)))
+	}
+	for _, fe := range e.Errors {
+		b.WriteString("\n  ")
+		b.WriteString(fe.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the individual field errors so they can be found with errors.As.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// validator collects field errors.
+type validator struct {
+	errs []*FieldError
+}
+
+// check records err, if any, against the named field.
+func (v *validator) check(field string, err error) {
+	if err == nil {
+		return
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		fe = &FieldError{Reason: err.Error()}
+	}
+	fe.Field = field
+	v.errs = append(v.errs, fe)
+}
+
+// err returns a *ValidationError if any errors were recorded, otherwise nil.
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errs}
+}
+
+// validateEnum checks v is blank or one of the allowed values.
+func validateEnum(v string, allowed []string) error {
+	if v == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+	return &FieldError{Value: v, Allowed: allowed}
+}
+
+// fieldRules check the value of a single field, in the order Validate reports them.
+var fieldRules = []struct {
+	field string
+	check func(c *ComicInfo) error
+}{
+	{"AgeRating", func(c *ComicInfo) error { return c.AgeRating.validate() }},
+	{"BlackAndWhite", func(c *ComicInfo) error { return c.BlackAndWhite.validate() }},
+	{"Manga", func(c *ComicInfo) error { return c.Manga.validate() }},
+	{"GTIN", func(c *ComicInfo) error { return validateGTIN(c.GTIN) }},
+	{"Count", func(c *ComicInfo) error { return validateCount(c.Count) }},
+	{"Volume", func(c *ComicInfo) error { return validateCount(c.Volume) }},
+	{"AlternateCount", func(c *ComicInfo) error { return validateCount(c.AlternativeCount) }},
+	{"Year", func(c *ComicInfo) error { return validateOptionalRange(c.Year, 1, 9999) }},
+	{"Month", func(c *ComicInfo) error { return validateOptionalRange(c.Month, 1, 12) }},
+	{"Day", func(c *ComicInfo) error { return validateOptionalRange(c.Day, 1, 31) }},
+	{"PageCount", func(c *ComicInfo) error { return validateRange(c.PageCount, 0, math.MaxInt32) }},
+	{"CommunityRating", func(c *ComicInfo) error { return c.CommunityRating.validate() }},
+}
+
+// Validate checks a value of the field on its own, with the rules ComicInfo.Validate applies to it.
+// Rules that involve other fields, such as Day requiring Month, are not checked, except within a Date.
+// The value must have the type returned by Get. An invalid value returns a *FieldError.
+func (f *Field) Validate(value any) error {
+	c := ComicInfo{}
+	if err := f.Set(&c, value); err != nil {
+		return err
+	}
+	v := validator{}
+	if d, ok := value.(Date); ok {
+		if err := d.validate(); err != nil {
+			v.check(f.Name, &FieldError{Value: d, Reason: err.Error()})
+		}
+	}
+	for _, r := range fieldRules {
+		if r.field == f.Name {
+			v.check(f.Name, r.check(&c))
+		}
+	}
+	if len(v.errs) > 0 {
+		return v.errs[0]
+	}
+	return nil
+}
+
+// Validate checks every field of a ComicInfo. All invalid fields are reported in a *ValidationError.
+func (c *ComicInfo) Validate() error {
+	v := validator{}
+
+	v.check("Version", c.Version.validate())
+	if c.Version != "" && c.Version.Before(c.RequiredVersion()) {
+		for _, f := range c.newerFields(c.Version) {
+			v.check(f.Name, fmt.Errorf("requires schema version %s, but version is %s", f.Version, c.Version))
+		}
+	}
+
+	for _, r := range fieldRules {
+		v.check(r.field, r.check(c))
+	}
+
+	// A partial date must be filled from the year down.
+	if c.Day > 0 && c.Month <= 0 {
+		v.check("Day", &FieldError{Value: c.Day, Reason: "Day requires Month"})
+	}
+	if c.Month > 0 && c.Year <= 0 {
+		v.check("Month", &FieldError{Value: c.Month, Reason: "Month requires Year"})
+	}
+	if c.Month >= 1 && c.Month <= 12 && c.Day >= 1 && c.Day <= 31 {
+		year := c.Year
+		if year <= 0 {
+			year = 2000 // a leap year, so any day that exists in some year is allowed
+		}
+		if n := daysIn(year, c.Month); c.Day > n {
+			v.check("Day", &FieldError{Value: c.Day, Reason: fmt.Sprintf("%s has %d days", time.Month(c.Month), n)})
+		}
+	}
+
+	if c.PageCount > 0 && len(c.Pages) > 0 && c.PageCount != int64(len(c.Pages)) {
+		v.check("PageCount", &FieldError{Value: c.PageCount, Reason: fmt.Sprintf("does not match the %d Pages", len(c.Pages))})
+	}
+
+	images := map[int]int{}
+	for i, p := range c.Pages {
+		path := fmt.Sprintf("Pages[%d]", i)
+		v.check(path+".Type", p.Type.validate())
+		v.check(path+".ImageSize", validateRange(p.ImageSize, 0, math.MaxInt64))
+		v.check(path+".ImageWidth", validateRange(int64(p.ImageWidth), 0, math.MaxInt32))
+		v.check(path+".ImageHeight", validateRange(int64(p.ImageHeight), 0, math.MaxInt32))
+
+		if p.Image < 0 {
+			v.check(path+".Image", &FieldError{Value: p.Image, Reason: "must not be negative"})
+		} else if c.PageCount > 0 && int64(p.Image) >= c.PageCount {
+			v.check(path+".Image", &FieldError{Value: p.Image, Reason: fmt.Sprintf("must be less than PageCount %d", c.PageCount)})
+		}
+		if first, ok := images[p.Image]; ok {
+			v.check(path+".Image", &FieldError{Value: p.Image, Reason: fmt.Sprintf("duplicates Pages[%d].Image", first)})
+		} else {
+			images[p.Image] = i
+		}
+	}
+
+	return v.err()
+}
+
+func (r Rating) validate() error {
+	if r < 0 || r > 5 {
+		return &FieldError{Value: float64(r), Reason: "must be between 0 and 5"}
+	}
+	// The XSD allows a single fraction digit.
+	if tenths := float64(r) * 10; math.Abs(tenths-math.Round(tenths)) > 1e-9 {
+		return &FieldError{Value: float64(r), Reason: "must have at most one decimal place"}
+	}
+	return nil
+}
+
+// validateRange checks min <= v <= max.
+func validateRange(v, min, max int64) error {
+	if v < min || v > max {
+		return &FieldError{Value: v, Reason: fmt.Sprintf("must be between %d and %d", min, max)}
+	}
+	return nil
+}
+
+// validateOptionalRange checks min <= v <= max, unless v is 0 (omitted) or -1 (the XSD default for unknown values).
+func validateOptionalRange(v, min, max int64) error {
+	if v == 0 || v == -1 {
+		return nil
+	}
+	return validateRange(v, min, max)
+}
+
+// validateCount checks a count isn't negative, other than -1 which the XSD uses for unknown values.
+func validateCount(v int64) error {
+	return validateOptionalRange(v, 0, math.MaxInt32)
+}
+
+// validateGTIN checks a GTIN is an 8, 12, 13 or 14 digit number, or an ISBN-10.
+// Hyphens and spaces are allowed as separators.
+func validateGTIN(gtin string) error {
+	if gtin == "" {
+		return nil
+	}
+	digits := strings.NewReplacer("-", "", " ", "").Replace(gtin)
+	for i, r := range digits {
+		isbn10Check := i == 9 && len(digits) == 10 && (r == 'X' || r == 'x')
+		if (r < '0' || r > '9') && !isbn10Check {
+			return &FieldError{Value: gtin, Reason: "must only contain digits"}
+		}
+	}
+	switch len(digits) {
+	case 8, 10, 12, 13, 14:
+	default:
+		return &FieldError{Value: gtin, Reason: "must have 8, 10, 12, 13 or 14 digits"}
+	}
+	return nil
+}
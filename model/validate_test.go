@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestComicInfo_Validate_collectsAll(t *testing.T) {
+	info := ComicInfo{
+		AgeRating: "Nope",
+		Manga:     "yes",
+		Pages: []ComicPageInfo{
+			{Image: 0, Type: "Story"},
+			{Image: 1, Type: "Cover"},
+		},
+	}
+
+	err := info.Validate()
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+	}
+
+	var fields []string
+	for _, fe := range verr.Errors {
+		fields = append(fields, fe.Field)
+	}
+	want := []string{"AgeRating", "Manga", "Pages[1].Type"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("Validate() fields = %v, want %v", fields, want)
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("Validate() error = %v, want a *FieldError", err)
+	}
+	if fe.Field != "AgeRating" || fe.Value != "Nope" || !reflect.DeepEqual(fe.Allowed, ageRatingValues) {
+		t.Errorf("Validate() first error = %+v", fe)
+	}
+}
+
+func TestComicInfo_Validate_valid(t *testing.T) {
+	info := ComicInfo{AgeRating: "M", Manga: "Yes", Pages: []ComicPageInfo{{Image: 0, Type: "FrontCover"}}}
+	if err := info.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestFieldError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  FieldError
+		want string
+	}{
+		{"Enum", FieldError{Field: "Manga", Value: "yes", Allowed: []string{"No", "Yes"}}, `Manga: invalid value "yes"; allowed values are "No", "Yes"`},
+		{"Reason", FieldError{Field: "GTIN", Value: "123", Reason: "too short"}, `GTIN: invalid value "123": too short`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComicInfo_Validate_rules(t *testing.T) {
+	tests := []struct {
+		name      string
+		info      ComicInfo
+		wantField string
+	}{
+		{"Valid date", ComicInfo{Year: 2021, Month: 3, Day: 14}, ""},
+		{"Unknown values", ComicInfo{Count: -1, Year: -1, Month: -1, Day: -1}, ""},
+		{"Month 13", ComicInfo{Year: 2021, Month: 13}, "Month"},
+		{"Day 32", ComicInfo{Year: 2021, Month: 1, Day: 32}, "Day"},
+		{"Day without Month", ComicInfo{Year: 2021, Day: 1}, "Day"},
+		{"Month without Year", ComicInfo{Month: 1}, "Month"},
+		{"Negative Count", ComicInfo{Count: -5}, "Count"},
+		{"Negative Volume", ComicInfo{Volume: -2}, "Volume"},
+		{"Negative PageCount", ComicInfo{PageCount: -1}, "PageCount"},
+		{"CommunityRating 9", ComicInfo{CommunityRating: 9}, "CommunityRating"},
+		{"CommunityRating 4.5", ComicInfo{CommunityRating: 4.5}, ""},
+		{"CommunityRating 4.55", ComicInfo{CommunityRating: 4.55}, "CommunityRating"},
+		{"PageCount matches Pages", ComicInfo{PageCount: 2, Pages: []ComicPageInfo{{Image: 0}, {Image: 1}}}, ""},
+		{"PageCount disagrees with Pages", ComicInfo{PageCount: 3, Pages: []ComicPageInfo{{Image: 0}, {Image: 1}}}, "PageCount"},
+		{"Duplicate Image", ComicInfo{Pages: []ComicPageInfo{{Image: 0}, {Image: 0}}}, "Pages[1].Image"},
+		{"Negative Image", ComicInfo{Pages: []ComicPageInfo{{Image: -1}}}, "Pages[0].Image"},
+		{"Image beyond PageCount", ComicInfo{PageCount: 1, Pages: []ComicPageInfo{{Image: 1}}}, "Pages[0].Image"},
+		{"Negative ImageWidth", ComicInfo{Pages: []ComicPageInfo{{Image: 0, ImageWidth: -1}}}, "Pages[0].ImageWidth"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.info.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			var fe *FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("Validate() error = %v, want a *FieldError", err)
+			}
+			if fe.Field != tt.wantField {
+				t.Errorf("Validate() field = %v, want %v (%v)", fe.Field, tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestField_Validate(t *testing.T) {
+	tests := []struct {
+		field   string
+		value   any
+		wantErr bool
+	}{
+		{"AgeRating", "M", false},
+		{"AgeRating", "PG-13", true},
+		{"GTIN", "9780785190219", false},
+		{"GTIN", "123", true},
+		{"Month", int64(12), false},
+		{"Month", int64(13), true},
+		{"CommunityRating", 4.5, false},
+		{"CommunityRating", 6.0, true},
+		{"Title", "Anything", false},
+		{"Date", Date{Year: 2021, Month: 2, Day: 28}, false},
+		{"Date", Date{Year: 2021, Month: 2, Day: 30}, true},
+		{"Month", "12", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			f, err := LookupField(tt.field)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = f.Validate(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%v) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
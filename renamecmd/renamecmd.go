@@ -1,7 +1,6 @@
 package renamecmd
 
 import (
-	"archive/zip"
 	"context"
 	"errors"
 	"flag"
@@ -12,6 +11,7 @@ import (
 	fsys "io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -26,6 +26,9 @@ type config struct {
 
 	// dryRun disables applying renames and just prints new names instead.
 	dryRun bool
+
+	// pad is the number of digits the whole part of an issue number is zero-padded to.
+	pad int
 }
 
 func New(out io.Writer) *ffcli.Command {
@@ -36,6 +39,7 @@ func New(out io.Writer) *ffcli.Command {
 	fs.BoolVar(&cfg.includeTitle, "t", false, "include comic title in file name.")
 	fs.BoolVar(&cfg.includeNumber, "n", false, "include comic number in file name.")
 	fs.BoolVar(&cfg.dryRun, "d", false, "dry-run")
+	fs.IntVar(&cfg.pad, "pad", 0, "zero-pad comic numbers to this many digits, e.g. 3 for #001.")
 
 	return &ffcli.Command{
 		Name:       "rename",
@@ -56,40 +60,52 @@ func (cfg *config) exec(_ context.Context, args []string) error {
 		}
 	}
 
+	// A file that can't be read or renamed is skipped, so the rest of the batch is still renamed
+	var errs []error
+	comics := make([]comic, 0, len(zipFileNames))
 	for _, name := range zipFileNames {
-		err := cfg.rename(name)
+		info, err := model.ReadFile(name)
 		if err != nil {
-			return fmt.Errorf("failed updating comic archive '%s': %w", name, err)
+			errs = append(errs, fmt.Errorf("failed reading comic archive '%s': %w", name, err))
+			continue
 		}
+		comics = append(comics, comic{name, info})
 	}
 
-	return nil
-}
-
-// rename computes the new name for a file, but doesn't rename the file.
-func (cfg *config) rename(fileName string) error {
+	// Rename in reading order
+	sort.SliceStable(comics, func(i, j int) bool {
+		return comics[i].less(comics[j])
+	})
 
-	zipFile, err := zip.OpenReader(fileName)
-	if err != nil {
-		return fmt.Errorf("failed opening zip file: %w", err)
-	}
-
-	var comicInfoFile *zip.File
-	for i, f := range zipFile.File {
-		if f.Name == model.ComicInfoXmlName {
-			comicInfoFile = zipFile.File[i]
+	for _, c := range comics {
+		err := cfg.rename(c.fileName, c.info)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed updating comic archive '%s': %w", c.fileName, err))
 		}
 	}
 
-	if comicInfoFile == nil {
-		return fmt.Errorf("no ComicInfo.xml file")
-	}
+	return errors.Join(errs...)
+}
 
-	comicInfo, err := model.Unmarshal(comicInfoFile)
-	if err != nil {
-		return fmt.Errorf("failed unmarshalling ComicInfo.xml: %w", err)
+// comic is a comic archive and its metadata.
+type comic struct {
+	fileName string
+	info     *model.ComicInfo
+}
+
+// less orders comics by series, then volume, then issue number.
+func (c comic) less(other comic) bool {
+	if s, o := strings.ToLower(c.info.Series), strings.ToLower(other.info.Series); s != o {
+		return s < o
+	}
+	if c.info.Volume != other.info.Volume {
+		return c.info.Volume < other.info.Volume
 	}
+	return c.info.IssueNumber().Less(other.info.IssueNumber())
+}
 
+// rename renames a file to a name computed from its metadata, or just prints the new name for a dry-run.
+func (cfg *config) rename(fileName string, comicInfo *model.ComicInfo) error {
 	inferredFileName, err := cfg.inferFileName(comicInfo)
 	if err != nil {
 		return fmt.Errorf("failed inferring name: %w", err)
@@ -109,7 +125,7 @@ func (cfg *config) rename(fileName string) error {
 	}
 
 	if cfg.dryRun {
-		fmt.Printf("Dry-run: would rename '%s' to '%s'\n", fileName, newPath)
+		_, _ = fmt.Fprintf(cfg.out, "Dry-run: would rename '%s' to '%s'\n", fileName, newPath)
 	} else {
 		err = os.Rename(fileName, newPath)
 		if err != nil {
@@ -135,7 +151,7 @@ func (cfg *config) inferFileName(c *model.ComicInfo) (string, error) {
 	// If there is _no_ Volume but there is a Number, then include Number anyway.
 	if (cfg.includeNumber && c.Number != "") || (c.Number != "" && c.Volume == 0) {
 		b.WriteString(" #")
-		b.WriteString(c.Number)
+		b.WriteString(c.IssueNumber().Format(cfg.pad))
 	}
 
 	if cfg.includeTitle && c.Title != "" && c.Series != "" {
@@ -1,15 +1,24 @@
 package renamecmd
 
 import (
+	"bytes"
+	"context"
+	"github.com/blissd/cbz/internal/ziptest"
 	"github.com/blissd/cbz/model"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
 func Test_config_inferFileName(t *testing.T) {
 	type fields struct {
-		out          io.Writer
-		includeTitle bool
+		out           io.Writer
+		includeTitle  bool
+		includeNumber bool
+		pad           int
 	}
 	type args struct {
 		c *model.ComicInfo
@@ -23,13 +32,17 @@ func Test_config_inferFileName(t *testing.T) {
 	}{
 		{"Series v01", fields{}, args{&model.ComicInfo{Series: "Series", Title: "Title", Volume: 1}}, "Series v01", false},
 		{"Series v01 - Title", fields{includeTitle: true}, args{&model.ComicInfo{Series: "Series", Title: "Title", Volume: 1}}, "Series v01 - Title", false},
-		{"Series v01 #2", fields{}, args{&model.ComicInfo{Series: "Series", Volume: 1, Number: "2"}}, "Series v01 #2", false},
+		{"Series v01 #2", fields{includeNumber: true}, args{&model.ComicInfo{Series: "Series", Volume: 1, Number: "2"}}, "Series v01 #2", false},
+		{"Series #002", fields{pad: 3}, args{&model.ComicInfo{Series: "Series", Number: "2"}}, "Series #002", false},
+		{"Series #001.5", fields{pad: 3}, args{&model.ComicInfo{Series: "Series", Number: "1.5"}}, "Series #001.5", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := &config{
-				out:          io.Discard,
-				includeTitle: tt.fields.includeTitle,
+				out:           io.Discard,
+				includeTitle:  tt.fields.includeTitle,
+				includeNumber: tt.fields.includeNumber,
+				pad:           tt.fields.pad,
 			}
 			got, err := cfg.inferFileName(tt.args.c)
 			if (err != nil) != tt.wantErr {
@@ -42,3 +55,44 @@ func Test_config_inferFileName(t *testing.T) {
 		})
 	}
 }
+
+func Test_comic_less(t *testing.T) {
+	comics := []comic{
+		{"d", &model.ComicInfo{Series: "Series", Volume: 2, Number: "1"}},
+		{"c", &model.ComicInfo{Series: "Series", Volume: 1, Number: "10"}},
+		{"b", &model.ComicInfo{Series: "Series", Volume: 1, Number: "2"}},
+		{"a", &model.ComicInfo{Series: "Another", Number: "3"}},
+	}
+	sort.SliceStable(comics, func(i, j int) bool {
+		return comics[i].less(comics[j])
+	})
+
+	got := ""
+	for _, c := range comics {
+		got += c.fileName
+	}
+	if got != "abcd" {
+		t.Errorf("sorted = %v, want abcd", got)
+	}
+}
+
+func Test_config_exec_skipsUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.cbz")
+	bad := filepath.Join(dir, "bad.cbz")
+
+	ziptest.Write(t, good, "", ziptest.Entry{Name: model.ComicInfoXmlName, Content: `<ComicInfo><Series>Series</Series><Number>1</Number></ComicInfo>`})
+	if err := os.WriteFile(bad, []byte("not a zip file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	cfg := config{out: &out, dryRun: true}
+	err := cfg.exec(context.Background(), []string{bad, good})
+	if err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("exec() error = %v, want an error naming %s", err, bad)
+	}
+	if want := "would rename '" + good + "' to '" + filepath.Join(dir, "Series #1.cbz") + "'"; !strings.Contains(out.String(), want) {
+		t.Errorf("exec() output = %q, want it to contain %q", out.String(), want)
+	}
+}